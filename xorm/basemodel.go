@@ -0,0 +1,115 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/go-xorm/core"
+	"github.com/lunny/log"
+)
+
+const baseModelName = "base_model"
+
+var (
+	// baseModel holds the columns of the BaseModel struct given by
+	// -base-model, it's nil when there is none.
+	baseModel *core.Table
+	// baseTables are the tables embedding BaseModel.
+	baseTables = make(map[*core.Table]bool)
+)
+
+// commonColumns returns the names of the columns which all the tables have
+// with the same Go type, SQL type and primary key, in the order of the
+// first table.
+func commonColumns(tables []*core.Table) []string {
+	if len(tables) < 2 {
+		return nil
+	}
+
+	var names []string
+	for _, col := range tables[0].Columns() {
+		common := true
+		for _, table := range tables[1:] {
+			if c := table.GetColumn(col.Name); c == nil || !sameColumn(c, col) {
+				common = false
+				break
+			}
+		}
+		if common {
+			names = append(names, col.Name)
+		}
+	}
+	return names
+}
+
+// sameColumn reports whether the columns have the same Go type, SQL type
+// and primary key, so one field of BaseModel stands for both.
+func sameColumn(a, b *core.Column) bool {
+	return goTypeString(a) == goTypeString(b) && sqlTypeString(a) == sqlTypeString(b) &&
+		a.IsPrimaryKey == b.IsPrimaryKey
+}
+
+// newBaseModel sets baseModel to a table of the named columns, which is
+// embedded by every table having all of them like the first one does. It's
+// left nil when no table has them.
+func newBaseModel(tables []*core.Table, names []string) {
+	baseModel = nil
+	baseTables = make(map[*core.Table]bool)
+	if len(names) == 0 {
+		return
+	}
+
+	// the columns are compared with the ones of the first table embedding
+	// BaseModel
+	var first *core.Table
+	for _, table := range tables {
+		has := true
+		for _, name := range names {
+			col := table.GetColumn(name)
+			if col == nil {
+				has = false
+				break
+			}
+			if first != nil && !sameColumn(col, first.GetColumn(name)) {
+				log.Warnf("table %v doesn't embed BaseModel since its column %v differs from the one of BaseModel",
+					table.Name, name)
+				has = false
+				break
+			}
+		}
+		if !has {
+			continue
+		}
+		baseTables[table] = true
+
+		if first == nil {
+			first = table
+			baseModel = core.NewEmptyTable()
+			baseModel.Name = baseModelName
+			for _, name := range names {
+				// the indexes belong to the table, not to BaseModel
+				orig := table.GetColumn(name)
+				col := *orig
+				col.TableName = baseModelName
+				col.Indexes = make(map[string]int)
+				if dir, ok := columnDirections[orig]; ok {
+					columnDirections[&col] = dir
+				}
+				unsignedColumns[&col] = unsignedColumns[orig]
+				baseModel.AddColumn(&col)
+			}
+		}
+	}
+}
+
+// embedsBase reports whether the table embeds BaseModel.
+func embedsBase(table *core.Table) bool {
+	return baseTables[table]
+}
+
+// isBaseColumn reports whether the column of the table comes from the
+// embedded BaseModel.
+func isBaseColumn(table *core.Table, col *core.Column) bool {
+	return baseTables[table] && baseModel.GetColumn(col.Name) != nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-xorm/core"
+)
+
+// baseTestTables returns two tables sharing an id and a created column.
+func baseTestTables() []*core.Table {
+	return []*core.Table{
+		newTable("order",
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+			&core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}, Nullable: true},
+			&core.Column{Name: "amount", SQLType: core.SQLType{Name: core.Int}}),
+		newTable("user",
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+			&core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}, Nullable: true},
+			&core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}, Length: 20}),
+	}
+}
+
+func TestBaseModelFiles(t *testing.T) {
+	setFlag(t, &genNullablePtr, true)
+	t.Cleanup(func() { newBaseModel(nil, nil) })
+
+	blocks := []*bool{&genAccessors, &genOrEmpty, &genRepository, &genSliceTypes, new(bool)}
+	for _, flag := range blocks {
+		setFlag(t, flag, true)
+		tables := baseTestTables()
+		newBaseModel(tables, commonColumns(tables))
+		if !reflect.DeepEqual(baseModel.ColumnsSeq(), []string{"id", "created"}) {
+			t.Fatalf("BaseModel columns %v", baseModel.ColumnsSeq())
+		}
+
+		for _, dir := range []string{"goxorm", "gomeddler"} {
+			var srcs []string
+			for _, table := range tables {
+				tbs := []*core.Table{table}
+				srcs = append(srcs, renderGo(t, dir, &Tmpl{Tables: tbs, Imports: genGoImports(tbs),
+					Accessors: genAccessors, OrEmpty: genOrEmpty, Repository: genRepository,
+					SliceTypes: genSliceTypes}))
+			}
+			tbs := []*core.Table{baseModel}
+			srcs = append(srcs, renderGo(t, dir, &Tmpl{Imports: genGoImports(tbs), BaseModel: baseModel}))
+			checkGo(t, srcs...)
+		}
+		*flag = false
+	}
+}
+
+func TestEmbedTag(t *testing.T) {
+	t.Cleanup(func() { newBaseModel(nil, nil) })
+	tables := baseTestTables()
+	newBaseModel(tables, commonColumns(tables))
+
+	for _, gorm := range []bool{false, true} {
+		setFlag(t, &genGorm, gorm)
+		want := "`xorm:\"extends\"`"
+		if gorm {
+			want = "`gorm:\"embedded\"`"
+		}
+		if got := embedTag(); got != want {
+			t.Errorf("-gorm=%v: embed tag %s, want %s", gorm, got, want)
+		}
+
+		tbs := tables[:1]
+		src := renderGo(t, "goxorm", &Tmpl{Tables: tbs, Imports: genGoImports(tbs)})
+		if !strings.Contains(src, "BaseModel "+want) {
+			t.Errorf("-gorm=%v: BaseModel isn't embedded by %s\n%s", gorm, want, src)
+		}
+	}
+}
+
+func TestBaseColumnsTypes(t *testing.T) {
+	t.Cleanup(func() { newBaseModel(nil, nil) })
+
+	tables := baseTestTables()
+	tables = append(tables, newTable("log",
+		&core.Column{Name: "id", SQLType: core.SQLType{Name: core.Varchar}, Length: 36, IsPrimaryKey: true},
+		&core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}, Nullable: true}))
+	newBaseModel(tables, []string{"id", "created"})
+
+	for i, want := range []bool{true, true, false} {
+		if got := embedsBase(tables[i]); got != want {
+			t.Errorf("%s embeds BaseModel: %v, want %v", tables[i].Name, got, want)
+		}
+	}
+}
+
+func TestBaseColumnsUnsigned(t *testing.T) {
+	t.Cleanup(func() { newBaseModel(nil, nil) })
+
+	var tables []*core.Table
+	for _, name := range []string{"order", "user"} {
+		tables = append(tables, newTable(name,
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: "INT UNSIGNED"}, IsPrimaryKey: true},
+			&core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}, Length: 20}))
+	}
+	normalizeUnsigned(tables)
+	newBaseModel(tables, []string{"id"})
+
+	col := baseModel.GetColumn("id")
+	if got := typestring(col); got != "uint32" {
+		t.Errorf("BaseModel id type %s, want uint32", got)
+	}
+	if got := sqlTypeString(col); got != "INT UNSIGNED" {
+		t.Errorf("BaseModel id xorm type %s, want INT UNSIGNED", got)
+	}
+}
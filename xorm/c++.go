@@ -13,7 +13,7 @@ import (
 
 var (
 	CPlusTmpl LangTmpl = LangTmpl{
-		template.FuncMap{"Mapper": mapper.Table2Obj,
+		template.FuncMap{"Mapper": mapName,
 			"Type":    cPlusTypeStr,
 			"UnTitle": unTitle,
 		},
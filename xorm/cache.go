@@ -0,0 +1,168 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-xorm/core"
+)
+
+// TableCache is the file of -changed-cache, it records the signatures of
+// the tables and of the options of the last run.
+type TableCache struct {
+	Options string            `json:"options"`
+	Tables  map[string]string `json:"tables"`
+}
+
+// readTableCache reads the cache file, a missing file is an empty cache.
+func readTableCache(f string) (*TableCache, error) {
+	c := &TableCache{Tables: make(map[string]string)}
+	bts, err := ioutil.ReadFile(f)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(bts, c); err != nil {
+		return nil, fmt.Errorf("%s: %v", f, err)
+	}
+	return c, nil
+}
+
+// writeTableCache writes the cache file.
+func writeTableCache(f string, c *TableCache) error {
+	bts, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f, append(bts, '\n'), 0644)
+}
+
+// newTableCache returns the cache of the tables, keyed by their real names
+// since the generated names depend on the options. The file names of the
+// tables are part of their signatures, since a new table can take the name
+// of the file of another.
+func newTableCache(options string, tables []*core.Table, fileNames map[*core.Table]string) *TableCache {
+	c := &TableCache{Options: options, Tables: make(map[string]string, len(tables))}
+	for _, table := range tables {
+		c.Tables[realTableName(table)] = tableSignature(table, fileNames[table])
+	}
+	return c
+}
+
+// changed returns the tables whose signature differs from the one of the
+// old cache or which aren't in it. All of them changed when the options
+// did.
+func (c *TableCache) changed(old *TableCache, tables []*core.Table) map[*core.Table]bool {
+	res := make(map[*core.Table]bool)
+	for _, table := range tables {
+		name := realTableName(table)
+		if c.Options != old.Options || c.Tables[name] != old.Tables[name] {
+			res[table] = true
+		}
+	}
+	return res
+}
+
+// tableSignature returns a hash of everything of the table the generated
+// code depends on: its name, its columns in their order and its indexes,
+// and what depends on the other tables too, which are the name of its file
+// and the columns of the BaseModel it embeds. The maps are written sorted
+// so the hash is stable.
+func tableSignature(table *core.Table, fileName string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "table %q %q %q %q %q %q %q\n", table.Name, realTableName(table), table.Comment,
+		table.PrimaryKeys, table.AutoIncrement, table.Version, fileName)
+	if embedsBase(table) {
+		fmt.Fprintf(h, "base %s\n", tableSignature(baseModel, ""))
+	}
+	for _, col := range table.Columns() {
+		fmt.Fprintf(h, "column %q %q %d %d %d %d %t %t %q %t %t %t %t %t %t %t %t %q\n",
+			col.Name, col.SQLType.Name, col.SQLType.DefaultLength, col.SQLType.DefaultLength2,
+			col.Length, col.Length2, unsignedColumns[col], col.Nullable, col.Default, col.DefaultIsEmpty,
+			col.IsPrimaryKey, col.IsAutoIncrement, col.IsCreated, col.IsUpdated, col.IsDeleted,
+			col.IsVersion, col.IsJSON, col.Comment)
+		writeSortedMap(h, "indexes", col.Indexes)
+		writeSortedMap(h, "enum", col.EnumOptions)
+		writeSortedMap(h, "set", col.SetOptions)
+		if len(col.EnumOptions) > 0 || len(col.SetOptions) > 0 {
+			// the type is renamed by a clashing table
+			fmt.Fprintf(h, "type %q\n", enumTypeName(col))
+		}
+	}
+
+	names := make([]string, 0, len(table.Indexes))
+	for name := range table.Indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		index := table.Indexes[name]
+		fmt.Fprintf(h, "index %q %d %q\n", index.Name, index.Type, index.Cols)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeSortedMap writes the entries of m to h in the order of their keys.
+func writeSortedMap(h hash.Hash, kind string, m map[string]int) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	fmt.Fprintf(h, "%s %d", kind, len(keys))
+	for _, k := range keys {
+		fmt.Fprintf(h, " %q=%d", k, m[k])
+	}
+	fmt.Fprintln(h)
+}
+
+// optionsSignature returns a hash of the command line and of the content
+// of the files it names, so a changed template or type map regenerates
+// every table. Missing files hash as empty.
+func optionsSignature(args []string, dirs []string, files []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "args %q\n", args)
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		filepath.Walk(dir, func(f string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				files = append(files, f)
+			}
+			return nil
+		})
+	}
+	for _, f := range files {
+		if f == "" {
+			continue
+		}
+		bts, _ := ioutil.ReadFile(f)
+		fmt.Fprintf(h, "file %q %d\n", f, len(bts))
+		h.Write(bts)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// changedNames returns the sorted real names of the changed tables.
+func changedNames(changed map[*core.Table]bool) string {
+	names := make([]string, 0, len(changed))
+	for table := range changed {
+		names = append(names, realTableName(table))
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,38 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/go-xorm/core"
+)
+
+func TestTableCacheCrossTable(t *testing.T) {
+	t.Cleanup(func() { newBaseModel(nil, nil) })
+
+	tables := baseTestTables()
+	order := tables[0]
+	names := fileNames(tables, "", nil)
+	newBaseModel(tables, []string{"id"})
+	old := newTableCache("options", tables, names)
+
+	// a new table taking the name of the file of order
+	renamed := map[*core.Table]string{order: "order_2", tables[1]: names[tables[1]]}
+	if changed := newTableCache("options", tables, renamed).changed(old, tables); !changed[order] || changed[tables[1]] {
+		t.Errorf("changed tables %s after a file rename, want order", changedNames(changed))
+	}
+
+	// a column more in BaseModel changes all the tables embedding it
+	newBaseModel(tables, []string{"id", "created"})
+	if changed := newTableCache("options", tables, names).changed(old, tables); len(changed) != 2 {
+		t.Errorf("changed tables %s after a BaseModel change, want all", changedNames(changed))
+	}
+
+	newBaseModel(tables, []string{"id"})
+	if changed := newTableCache("options", tables, names).changed(old, tables); len(changed) != 0 {
+		t.Errorf("changed tables %s, want none", changedNames(changed))
+	}
+}
@@ -29,6 +29,9 @@ type Command struct {
 
 	// Flag is a set of flags specific to this command.
 	Flags map[string]bool
+
+	// Options is a set of flags taking a value, given as -name=value.
+	Options map[string]string
 }
 
 // Name returns the command's name: the first word in the usage line.
@@ -54,7 +57,7 @@ func (c *Command) Runnable() bool {
 }
 
 // checkFlags checks if the flag exists with correct format.
-func checkFlags(flags map[string]bool, args []string, print func(string)) int {
+func checkFlags(flags map[string]bool, options map[string]string, args []string, print func(string)) int {
 	num := 0 // Number of valid flags, use to cut out.
 	for i, f := range args {
 		// Check flag prefix '-'.
@@ -63,6 +66,18 @@ func checkFlags(flags map[string]bool, args []string, print func(string)) int {
 			break
 		}
 
+		// Check if it a valid option with value.
+		if n := strings.Index(f, "="); n > 0 {
+			if _, ok := options[f[:n]]; !ok {
+				fmt.Printf("[ERRO] Unknown flag: %s.\n", f)
+				return -1
+			}
+			options[f[:n]] = f[n+1:]
+			print(f)
+			num = i + 1
+			continue
+		}
+
 		// Check if it a valid flag.
 		if v, ok := flags[f]; ok {
 			flags[f] = !v
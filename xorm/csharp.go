@@ -0,0 +1,86 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+var (
+	CSharpTmpl LangTmpl = LangTmpl{
+		template.FuncMap{"Mapper": mapName,
+			"Type":    cSharpTypeStr,
+			"UnTitle": unTitle,
+		},
+		formatBraces,
+		genCSharpImports,
+	}
+)
+
+// cSharpValueTypes are the C# types which become nullable by a ?.
+var cSharpValueTypes = map[string]bool{
+	"int":      true,
+	"long":     true,
+	"float":    true,
+	"double":   true,
+	"decimal":  true,
+	"bool":     true,
+	"DateTime": true,
+	"TimeSpan": true,
+}
+
+func cSharpTypeStr(col *core.Column) string {
+	s := cSharpType(col.SQLType)
+	if col.Nullable && !col.IsPrimaryKey && cSharpValueTypes[s] {
+		return s + "?"
+	}
+	return s
+}
+
+func cSharpType(tp core.SQLType) string {
+	name := strings.ToUpper(tp.Name)
+	switch name {
+	case core.Bit, core.TinyInt, core.SmallInt, core.MediumInt, core.Int, core.Integer, core.Serial:
+		return "int"
+	case core.BigInt, core.BigSerial:
+		return "long"
+	case core.Date, core.DateTime, core.TimeStamp, core.TimeStampz:
+		return "DateTime"
+	case core.Time:
+		return "TimeSpan"
+	case core.Decimal, core.Numeric:
+		return "decimal"
+	case core.Real, core.Float:
+		return "float"
+	case core.Double:
+		return "double"
+	case core.Binary, core.VarBinary, core.TinyBlob, core.Blob, core.MediumBlob, core.LongBlob, core.Bytea:
+		return "byte[]"
+	case core.Bool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+func genCSharpImports(tables []*core.Table) map[string]string {
+	imports := map[string]string{
+		"System.ComponentModel.DataAnnotations":        "System.ComponentModel.DataAnnotations",
+		"System.ComponentModel.DataAnnotations.Schema": "System.ComponentModel.DataAnnotations.Schema",
+	}
+
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			switch cSharpType(col.SQLType) {
+			case "DateTime", "TimeSpan":
+				imports["System"] = "System"
+			}
+		}
+	}
+	return imports
+}
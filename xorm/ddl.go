@@ -0,0 +1,569 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"github.com/go-xorm/core"
+)
+
+// ddlTables reads the tables of the CREATE TABLE statements in a DDL file,
+// as -ddl does instead of connecting to the database. Only MySQL DDL is
+// supported.
+func ddlTables(driverName, fileName string) ([]*core.Table, error) {
+	if driverName != "mysql" && driverName != "mymysql" {
+		return nil, fmt.Errorf("DDL files of %s are not supported, only mysql", driverName)
+	}
+
+	bs, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	return parseMysqlDDL(string(bs))
+}
+
+const (
+	ddlIdent = iota
+	ddlQuoted
+	ddlString
+	ddlNumber
+	ddlPunct
+)
+
+type ddlToken struct {
+	kind int
+	text string
+}
+
+// lexDDL splits MySQL DDL into tokens, dropping the comments. Quoted
+// identifiers and strings are unquoted, bit and hex literals like b'01'
+// are kept as they are.
+func lexDDL(src string) ([]ddlToken, error) {
+	var toks []ddlToken
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || isDDLLineComment(src[i:]):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case c == '`' || c == '"':
+			end := strings.IndexByte(src[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated identifier")
+			}
+			toks = append(toks, ddlToken{ddlQuoted, src[i+1 : i+1+end]})
+			i += end + 2
+		case c == '\'':
+			s, n, err := lexDDLString(src[i:])
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, ddlToken{ddlString, s})
+			i += n
+		case (c == 'b' || c == 'B' || c == 'x' || c == 'X') && i+1 < len(src) && src[i+1] == '\'':
+			end := strings.IndexByte(src[i+2:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated literal")
+			}
+			toks = append(toks, ddlToken{ddlNumber, src[i : i+3+end]})
+			i += end + 3
+		case isDDLIdentByte(c):
+			j := i
+			for j < len(src) && (isDDLIdentByte(src[j]) || src[j] == '.' && c >= '0' && c <= '9') {
+				j++
+			}
+			kind := ddlIdent
+			if c >= '0' && c <= '9' {
+				kind = ddlNumber
+			}
+			toks = append(toks, ddlToken{kind, src[i:j]})
+			i = j
+		default:
+			toks = append(toks, ddlToken{ddlPunct, string(c)})
+			i++
+		}
+	}
+	return toks, nil
+}
+
+// lexDDLString returns the content of the single quoted string src starts
+// with and the length of the quoted string.
+func lexDDLString(src string) (string, int, error) {
+	var buf []byte
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			if i+1 < len(src) {
+				i++
+				buf = append(buf, unescapeDDL(src[i]))
+			}
+		case '\'':
+			if i+1 < len(src) && src[i+1] == '\'' {
+				buf = append(buf, '\'')
+				i++
+				continue
+			}
+			return string(buf), i + 1, nil
+		default:
+			buf = append(buf, src[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
+
+func unescapeDDL(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	case '0':
+		return 0
+	}
+	return c
+}
+
+// isDDLLineComment reports whether src starts with a -- comment, which
+// needs a space after the dashes unless it ends the line.
+func isDDLLineComment(src string) bool {
+	if !strings.HasPrefix(src, "--") {
+		return false
+	}
+	return len(src) == 2 || strings.IndexByte(" \t\r\n", src[2]) >= 0
+}
+
+func isDDLIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+type ddlParser struct {
+	toks []ddlToken
+	pos  int
+}
+
+func (p *ddlParser) eof() bool {
+	return p.pos >= len(p.toks)
+}
+
+func (p *ddlParser) peek() ddlToken {
+	if p.eof() {
+		return ddlToken{ddlPunct, ""}
+	}
+	return p.toks[p.pos]
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.peek()
+	if !p.eof() {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the keywords if the next tokens are them, ignoring the
+// case. Quoted identifiers are never keywords.
+func (p *ddlParser) accept(words ...string) bool {
+	if p.pos+len(words) > len(p.toks) {
+		return false
+	}
+	for i, w := range words {
+		t := p.toks[p.pos+i]
+		if t.kind != ddlIdent || !strings.EqualFold(t.text, w) {
+			return false
+		}
+	}
+	p.pos += len(words)
+	return true
+}
+
+// acceptAny consumes the first of the keywords the next token is.
+func (p *ddlParser) acceptAny(words ...string) bool {
+	for _, w := range words {
+		if p.accept(w) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *ddlParser) acceptPunct(s string) bool {
+	if t := p.peek(); t.kind == ddlPunct && t.text == s {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// skipTo skips the tokens up to the first of the punctuations outside of
+// parentheses, which isn't consumed.
+func (p *ddlParser) skipTo(puncts ...string) {
+	depth := 0
+	for !p.eof() {
+		t := p.peek()
+		if t.kind == ddlPunct {
+			if depth == 0 {
+				for _, s := range puncts {
+					if t.text == s {
+						return
+					}
+				}
+			}
+			switch t.text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			}
+		}
+		p.pos++
+	}
+}
+
+// name reads a possibly qualified name and returns its last part.
+func (p *ddlParser) name() string {
+	name := p.next().text
+	for p.acceptPunct(".") {
+		name = p.next().text
+	}
+	return name
+}
+
+// parseMysqlDDL returns the tables of the CREATE TABLE statements of MySQL
+// DDL, like a dump of mysqldump. The other statements are ignored.
+func parseMysqlDDL(src string) ([]*core.Table, error) {
+	toks, err := lexDDL(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ddlParser{toks: toks}
+	var tables []*core.Table
+	for !p.eof() {
+		if p.accept("CREATE") {
+			p.accept("TEMPORARY")
+			if p.accept("TABLE") {
+				table, err := p.parseTable()
+				if err != nil {
+					return nil, err
+				}
+				if table != nil {
+					tables = append(tables, table)
+				}
+			}
+		}
+		p.skipTo(";")
+		p.acceptPunct(";")
+	}
+	return tables, nil
+}
+
+// parseTable parses a CREATE TABLE statement after its TABLE keyword. It
+// returns nil for statements without column definitions, like CREATE
+// TABLE ... LIKE.
+func (p *ddlParser) parseTable() (*core.Table, error) {
+	p.accept("IF", "NOT", "EXISTS")
+	table := core.NewEmptyTable()
+	table.Name = p.name()
+	if !p.acceptPunct("(") {
+		return nil, nil
+	}
+
+	var (
+		cols    []*core.Column
+		pks     []string
+		indexes []*core.Index
+	)
+	for {
+		if p.accept("CONSTRAINT") {
+			if t := p.peek(); t.kind == ddlQuoted ||
+				t.kind == ddlIdent && !p.isKeyword("PRIMARY", "UNIQUE", "FOREIGN", "CHECK") {
+				p.next()
+			}
+		}
+
+		switch {
+		case p.accept("PRIMARY", "KEY"):
+			pks = p.parseKeyColumns()
+		case p.accept("UNIQUE"):
+			indexes = append(indexes, p.parseIndex(core.UniqueType))
+		case p.acceptAny("KEY", "INDEX"):
+			indexes = append(indexes, p.parseIndex(core.IndexType))
+		case p.acceptAny("FULLTEXT", "SPATIAL"):
+			indexes = append(indexes, p.parseIndex(core.IndexType))
+		case p.acceptAny("FOREIGN", "CHECK"):
+		default:
+			col, err := p.parseColumn(table.Name)
+			if err != nil {
+				return nil, err
+			}
+			if col.IsPrimaryKey {
+				pks = append(pks, col.Name)
+			}
+			if col.Indexes[col.Name] == core.UniqueType {
+				delete(col.Indexes, col.Name)
+				indexes = append(indexes, &core.Index{Name: col.Name, Type: core.UniqueType, Cols: []string{col.Name}})
+			}
+			cols = append(cols, col)
+		}
+
+		p.skipTo(",", ")")
+		if p.acceptPunct(",") {
+			continue
+		}
+		if p.acceptPunct(")") {
+			break
+		}
+		return nil, fmt.Errorf("table %s: unexpected end of the definitions", table.Name)
+	}
+
+	for _, col := range cols {
+		for _, pk := range pks {
+			if col.Name == pk {
+				col.IsPrimaryKey = true
+				col.Nullable = false
+			}
+		}
+		table.AddColumn(col)
+	}
+	// AddColumn adds the primary key in the order of the columns
+	table.PrimaryKeys = pks
+
+	for _, index := range indexes {
+		if index.Name == "" && len(index.Cols) > 0 {
+			index.Name = index.Cols[0]
+		}
+		// the indexes named by xorm are regular like the ones read from
+		// the database
+		for _, prefix := range []string{"IDX_", "UQE_"} {
+			if strings.HasPrefix(index.Name, prefix+table.Name+"_") {
+				index.Name = index.Name[len(prefix)+len(table.Name)+1:]
+				index.IsRegular = true
+			}
+		}
+		for _, name := range index.Cols {
+			col := table.GetColumn(name)
+			if col == nil {
+				return nil, fmt.Errorf("table %s: index %s has unknown column %s", table.Name, index.Name, name)
+			}
+			col.Indexes[index.Name] = index.Type
+		}
+		table.AddIndex(index)
+	}
+
+	p.parseTableOptions(table)
+	return table, nil
+}
+
+func (p *ddlParser) isKeyword(words ...string) bool {
+	t := p.peek()
+	for _, w := range words {
+		if t.kind == ddlIdent && strings.EqualFold(t.text, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIndex parses an index definition after its UNIQUE, KEY or INDEX
+// keyword. The name is empty when the definition has none.
+func (p *ddlParser) parseIndex(typ int) *core.Index {
+	p.acceptAny("KEY", "INDEX")
+	index := &core.Index{Type: typ}
+	if t := p.peek(); t.kind == ddlIdent && !p.isKeyword("USING") || t.kind == ddlQuoted {
+		index.Name = p.next().text
+	}
+	if p.accept("USING") {
+		p.next()
+	}
+	index.Cols = p.parseKeyColumns()
+	return index
+}
+
+// parseKeyColumns parses the parenthesized columns of a key, dropping
+// their prefix lengths and orders.
+func (p *ddlParser) parseKeyColumns() []string {
+	var cols []string
+	if !p.acceptPunct("(") {
+		return nil
+	}
+	for !p.eof() {
+		cols = append(cols, p.next().text)
+		p.skipTo(",", ")")
+		if p.acceptPunct(")") {
+			break
+		}
+		p.acceptPunct(",")
+	}
+	return cols
+}
+
+// parseColumn parses a column definition up to the comma or parenthesis
+// ending it.
+func (p *ddlParser) parseColumn(tableName string) (*core.Column, error) {
+	t := p.next()
+	if t.kind != ddlIdent && t.kind != ddlQuoted {
+		return nil, fmt.Errorf("table %s: unexpected %q", tableName, t.text)
+	}
+	col := &core.Column{
+		Name:           t.text,
+		TableName:      tableName,
+		Nullable:       true,
+		Indexes:        make(map[string]int),
+		DefaultIsEmpty: true,
+	}
+
+	typ := strings.ToUpper(p.next().text)
+	if typ == "DOUBLE" {
+		p.accept("PRECISION")
+	}
+	if p.acceptPunct("(") {
+		var args []ddlToken
+		for !p.eof() && !p.acceptPunct(")") {
+			if a := p.next(); a.kind != ddlPunct {
+				args = append(args, a)
+			}
+		}
+		switch typ {
+		case core.Enum, core.Set:
+			opts := make(map[string]int, len(args))
+			for i, a := range args {
+				opts[a.text] = i
+			}
+			if typ == core.Enum {
+				col.EnumOptions = opts
+			} else {
+				col.SetOptions = opts
+			}
+		default:
+			if len(args) > 0 {
+				col.Length, _ = strconv.Atoi(args[0].text)
+			}
+			if len(args) > 1 {
+				col.Length2, _ = strconv.Atoi(args[1].text)
+			}
+		}
+	}
+
+	var onUpdate bool
+	for !p.eof() {
+		if t := p.peek(); t.kind == ddlPunct && (t.text == "," || t.text == ")") {
+			break
+		}
+		switch {
+		case p.accept("UNSIGNED"):
+			// the modifier stays out of the type name the mappers know
+			unsignedColumns[col] = true
+		case p.accept("NOT", "NULL"):
+			col.Nullable = false
+		case p.accept("NULL"):
+			col.Nullable = true
+		case p.accept("DEFAULT"):
+			col.Default = p.parseDefault()
+			col.DefaultIsEmpty = false
+		case p.accept("ON", "UPDATE"):
+			p.parseDefault()
+			onUpdate = true
+		case p.accept("AUTO_INCREMENT"):
+			col.IsAutoIncrement = true
+		case p.accept("PRIMARY", "KEY"), p.accept("KEY"):
+			col.IsPrimaryKey = true
+		case p.accept("UNIQUE"):
+			p.accept("KEY")
+			col.Indexes[col.Name] = core.UniqueType
+		case p.accept("COMMENT"):
+			col.Comment = p.next().text
+		case p.accept("CHARACTER", "SET"), p.accept("CHARSET"), p.accept("COLLATE"):
+			p.next()
+		default:
+			p.next()
+			if p.acceptPunct("(") {
+				p.skipTo(")")
+				p.acceptPunct(")")
+			}
+		}
+	}
+
+	if onUpdate && col.Default != "" {
+		col.Default += " ON UPDATE CURRENT_TIMESTAMP"
+	}
+	if col.Default == "NULL" {
+		col.Default = ""
+		col.DefaultIsEmpty = true
+	}
+	col.SQLType = core.SQLType{Name: typ, DefaultLength: col.Length, DefaultLength2: col.Length2}
+	return col, nil
+}
+
+// parseDefault returns a default as it's read from the database: strings
+// are single quoted and expressions are kept as they are written.
+func (p *ddlParser) parseDefault() string {
+	t := p.next()
+	switch {
+	case t.kind == ddlString:
+		return "'" + strings.Replace(t.text, "'", "''", -1) + "'"
+	case t.kind == ddlPunct && t.text == "-":
+		return "-" + p.next().text
+	case t.kind == ddlPunct && t.text == "(":
+		start := p.pos
+		p.skipTo(")")
+		var parts []string
+		for _, a := range p.toks[start:p.pos] {
+			parts = append(parts, a.text)
+		}
+		p.acceptPunct(")")
+		return strings.Join(parts, "")
+	}
+
+	// function calls like CURRENT_TIMESTAMP(3)
+	if t.kind == ddlIdent && p.acceptPunct("(") {
+		start := p.pos
+		p.skipTo(")")
+		arg := ""
+		for _, a := range p.toks[start:p.pos] {
+			arg += a.text
+		}
+		p.acceptPunct(")")
+		return t.text + "(" + arg + ")"
+	}
+	return t.text
+}
+
+// parseTableOptions reads the engine, charset and comment of a table up to
+// the end of the statement.
+func (p *ddlParser) parseTableOptions(table *core.Table) {
+	for !p.eof() {
+		if t := p.peek(); t.kind == ddlPunct && t.text == ";" {
+			return
+		}
+		switch {
+		case p.accept("ENGINE"):
+			p.acceptPunct("=")
+			table.StoreEngine = p.next().text
+		case p.accept("CHARACTER", "SET"), p.accept("CHARSET"):
+			p.acceptPunct("=")
+			table.Charset = p.next().text
+		case p.accept("COMMENT"):
+			p.acceptPunct("=")
+			table.Comment = p.next().text
+		default:
+			p.next()
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"testing"
+)
+
+func TestDDLUnsigned(t *testing.T) {
+	tables, err := parseMysqlDDL("CREATE TABLE `user` (\n" +
+		"  `id` int(10) unsigned NOT NULL AUTO_INCREMENT,\n" +
+		"  `age` tinyint unsigned zerofill DEFAULT NULL,\n" +
+		"  `score` bigint(20) NOT NULL,\n" +
+		"  PRIMARY KEY (`id`)\n" +
+		");")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 {
+		t.Fatalf("%d tables, want 1", len(tables))
+	}
+
+	tests := []struct {
+		col      string
+		name     string
+		unsigned bool
+		csharp   string
+		goType   string
+	}{
+		{"id", "INT", true, "int", "uint32"},
+		{"age", "TINYINT", true, "int?", "uint8"},
+		{"score", "BIGINT", false, "long", "int64"},
+	}
+	for _, test := range tests {
+		col := tables[0].GetColumn(test.col)
+		if col == nil {
+			t.Fatalf("no column %s", test.col)
+		}
+		if col.SQLType.Name != test.name || unsignedColumns[col] != test.unsigned {
+			t.Errorf("%s: type %s unsigned %v, want %s unsigned %v", test.col,
+				col.SQLType.Name, unsignedColumns[col], test.name, test.unsigned)
+		}
+		if got := cSharpTypeStr(col); got != test.csharp {
+			t.Errorf("%s: C# type %s, want %s", test.col, got, test.csharp)
+		}
+		if got := goTypeString(col); got != test.goType {
+			t.Errorf("%s: Go type %s, want %s", test.col, got, test.goType)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/go-xorm/core"
+)
+
+// pgCastReg matches the type casts postgres appends to defaults, e.g.
+// 'abc'::character varying.
+var pgCastReg = regexp.MustCompile(`(::[\w ]+(\[\])?)+$`)
+
+// timestampDefaultReg matches the defaults setting the current time, e.g.
+// CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP.
+var timestampDefaultReg = regexp.MustCompile(`(?i)^(current_timestamp|now|localtimestamp)(\(\d*\))?` +
+	`(\s+on\s+update\s+(current_timestamp|now|localtimestamp)(\(\d*\))?)?$`)
+
+// sequenceDefaultReg matches the defaults of postgres serial columns, e.g.
+// nextval('users_id_seq'::regclass).
+var sequenceDefaultReg = regexp.MustCompile(`(?i)^nextval\(.*\)$`)
+
+// isSequenceDefault reports whether the column defaults to the next value
+// of a sequence, which makes it auto increment rather than a default.
+func isSequenceDefault(col *core.Column) bool {
+	return sequenceDefaultReg.MatchString(strings.TrimSpace(col.Default))
+}
+
+// isAutoIncr reports whether the column is auto increment, including the
+// postgres serial columns.
+func isAutoIncr(col *core.Column) bool {
+	return col.IsAutoIncrement || isSequenceDefault(col)
+}
+
+// sqlKeywordDefaults are defaults which are expressions, not literals.
+var sqlKeywordDefaults = map[string]bool{
+	"NULL":              true,
+	"CURRENT_TIMESTAMP": true,
+	"CURRENT_DATE":      true,
+	"CURRENT_TIME":      true,
+	"LOCALTIME":         true,
+	"LOCALTIMESTAMP":    true,
+}
+
+// defaultValue returns the default of the column as written in the xorm
+// tag. Strings are single quoted, numbers are bare, booleans become true
+// or false, and function calls like now() are kept as they are.
+func defaultValue(col *core.Column) string {
+	if isNow, _ := timestampDefault(col); isNow {
+		return "CURRENT_TIMESTAMP"
+	}
+
+	v := pgCastReg.ReplaceAllString(strings.TrimSpace(col.Default), "")
+	quoted := len(v) >= 2 && v[0] == '\'' && v[len(v)-1] == '\''
+	if !quoted && (strings.Contains(v, "(") || sqlKeywordDefaults[strings.ToUpper(v)]) {
+		return v
+	}
+
+	unquoted := v
+	if quoted {
+		unquoted = strings.Replace(v[1:len(v)-1], "''", "'", -1)
+	}
+
+	switch {
+	case isBool(col):
+		switch strings.ToLower(unquoted) {
+		case "1", "t", "true", "y", "yes", "on", "b'1'":
+			return "true"
+		case "0", "f", "false", "n", "no", "off", "b'0'":
+			return "false"
+		}
+		return unquoted
+	case isNumeric(col.SQLType):
+		return unquoted
+	}
+	return "'" + strings.Replace(unquoted, "'", "''", -1) + "'"
+}
+
+// timestampDefault reports whether a time column defaults to the current
+// time, and whether it's set to the current time on update too.
+func timestampDefault(col *core.Column) (bool, bool) {
+	if sqlType2Type(col.SQLType).String() != "time.Time" {
+		return false, false
+	}
+	m := timestampDefaultReg.FindStringSubmatch(strings.TrimSpace(col.Default))
+	if m == nil {
+		return false, false
+	}
+	return true, m[3] != ""
+}
+
+// isBool reports whether the column is generated as bool, which are the
+// BOOL and BOOLEAN columns, BIT(1) and TINYINT(1) with -tinyint1-bool.
+func isBool(col *core.Column) bool {
+	switch strings.ToUpper(col.SQLType.Name) {
+	case core.Bool, "BOOLEAN":
+		return true
+	case core.Bit:
+		return col.Length <= 1
+	case core.TinyInt:
+		return genTinyIntBool && col.Length == 1
+	}
+	return sqlType2Type(col.SQLType).Kind() == reflect.Bool
+}
+
+func isNumeric(st core.SQLType) bool {
+	if isInteger(st) || isDecimal(st) {
+		return true
+	}
+	switch sqlType2Type(st).Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-xorm/core"
+)
+
+func TestDefaultValue(t *testing.T) {
+	tests := []struct {
+		sqlType string
+		def     string
+		want    string
+	}{
+		// mysql
+		{core.Varchar, "abc", "'abc'"},
+		{core.Varchar, "it's", "'it''s'"},
+		{core.Int, "0", "0"},
+		{core.Decimal, "1.50", "1.50"},
+		{core.TinyInt, "1", "1"},
+		{core.Bit, "b'1'", "true"},
+		{core.DateTime, "CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"},
+		{core.Varchar, "uuid()", "uuid()"},
+		// postgres
+		{core.Varchar, "'abc'::character varying", "'abc'"},
+		{core.Varchar, "''::character varying", "''"},
+		{core.Text, "'{}'::text[]", "'{}'"},
+		{core.Integer, "42", "42"},
+		{core.Bool, "false", "false"},
+		{"BOOLEAN", "'t'", "true"},
+		{core.TimeStamp, "now()", "CURRENT_TIMESTAMP"},
+		{core.Varchar, "gen_random_uuid()", "gen_random_uuid()"},
+		{core.Varchar, "NULL", "NULL"},
+	}
+	for _, test := range tests {
+		col := &core.Column{Name: "c", SQLType: core.SQLType{Name: test.sqlType}, Default: test.def}
+		if got := defaultValue(col); got != test.want {
+			t.Errorf("%s default %s: got %s, want %s", test.sqlType, test.def, got, test.want)
+		}
+	}
+}
+
+func TestSequenceDefault(t *testing.T) {
+	tests := []struct {
+		def     string
+		autoinc bool
+	}{
+		{"nextval('users_id_seq'::regclass)", true},
+		{" NEXTVAL('users_id_seq') ", true},
+		{"0", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		col := &core.Column{Name: "id", SQLType: core.SQLType{Name: core.Integer}, IsPrimaryKey: true, Default: test.def}
+		table := newTable("users", col)
+		if got := isAutoIncr(col); got != test.autoinc {
+			t.Errorf("default %s: auto increment %v, want %v", test.def, got, test.autoinc)
+		}
+		if got := isInsertCol(col); got == test.autoinc {
+			t.Errorf("default %s: insert column %v", test.def, got)
+		}
+
+		opts := xormTag(table, col)
+		if got := hasOption(opts, "autoincr"); got != test.autoinc {
+			t.Errorf("default %s: xorm tag %v", test.def, opts)
+		}
+		if test.autoinc && hasOption(opts, "default") {
+			t.Errorf("default %s: xorm tag %v has the default", test.def, opts)
+		}
+
+		setFlag(t, &genGorm, true)
+		gorm := reflect.StructTag(gormTag(table, col)).Get("gorm")
+		if got := strings.Contains(gorm, "autoIncrement"); got != test.autoinc {
+			t.Errorf("default %s: gorm tag %s", test.def, gorm)
+		}
+		if test.autoinc && strings.Contains(gorm, "default:") {
+			t.Errorf("default %s: gorm tag %s has the default", test.def, gorm)
+		}
+		genGorm = false
+	}
+}
+
+func hasOption(opts []string, opt string) bool {
+	for _, o := range opts {
+		if o == opt {
+			return true
+		}
+	}
+	return false
+}
@@ -5,28 +5,73 @@
 package main
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"go/build/constraint"
 	"go/format"
+	"go/scanner"
+	"go/token"
+	"os"
+	"path"
 	"reflect"
+	"regexp"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-xorm/core"
+	"golang.org/x/tools/imports"
 )
 
 var (
 	supportComment bool
 	GoLangTmpl     LangTmpl = LangTmpl{
 		template.FuncMap{
-			"Mapper":   mapper.Table2Obj,
-			"Type":     typestring,
-			"Tag":      tag,
-			"UnTitle":  unTitle,
-			"gt":       gt,
-			"getCol":   getCol,
-			"distinct": distinct,
+			"Mapper":       goName,
+			"TypeName":     typeName,
+			"Type":         typestring,
+			"Tag":          tag,
+			"UnTitle":      unTitle,
+			"eq":           eq,
+			"ne":           ne,
+			"lt":           lt,
+			"le":           le,
+			"gt":           gt,
+			"ge":           ge,
+			"getCol":       getCol,
+			"hasCol":       hasCol,
+			"colOr":        colOr,
+			"distinct":     distinct,
+			"Enums":        enums,
+			"Sets":         sets,
+			"RealName":     realTableName,
+			"FieldDoc":     fieldDoc,
+			"FieldFacts":   fieldFacts,
+			"TableDoc":     tableDoc,
+			"IsPtr":        isPtr,
+			"Elem":         elemType,
+			"ImportGroups": importGroups,
+			"EmbedsBase":   embedsBase,
+			"IsBaseCol":    isBaseColumn,
+			"ColNames":     colNames,
+			"pkCols":       pkCols,
+			"autoIncrCol":  autoIncrCol,
+			"PKParams":     pkParams,
+			"SliceName":    sliceName,
+			"PluralName":   pluralName,
+			"InsertCols":   insertCols,
+			"maxNameLen":   maxNameLen,
+			"EmbedTag":     embedTag,
+			"singular":     singular,
+			"plural":       plural,
+			"snake":        snakeCase,
+			"camel":        camelCase,
+			"pascal":       pascalCase,
 		},
 		formatGo,
 		genGoImports,
@@ -39,6 +84,42 @@ var (
 	errNoComparison      = errors.New("missing argument for comparison")
 )
 
+// unsignedTypes maps the integer SQL types to the Go types used when
+// the column is unsigned.
+var unsignedTypes = map[string]string{
+	core.TinyInt:   "uint8",
+	core.SmallInt:  "uint16",
+	core.MediumInt: "uint32",
+	core.Int:       "uint32",
+	core.Integer:   "uint32",
+	core.BigInt:    "uint64",
+}
+
+// arrayElemTypes widens the element types of Postgres array columns to
+// the ones supported by the driver.
+var arrayElemTypes = map[string]string{
+	"int":     "int64",
+	"int8":    "int64",
+	"int16":   "int64",
+	"int32":   "int64",
+	"float32": "float64",
+}
+
+// sqlNullTypes maps the Go types of nullable columns to the database/sql
+// types used when -sql-null is given.
+var sqlNullTypes = map[string]string{
+	"string":    "sql.NullString",
+	"int":       "sql.NullInt64",
+	"int8":      "sql.NullInt64",
+	"int16":     "sql.NullInt64",
+	"int32":     "sql.NullInt64",
+	"int64":     "sql.NullInt64",
+	"float32":   "sql.NullFloat64",
+	"float64":   "sql.NullFloat64",
+	"bool":      "sql.NullBool",
+	"time.Time": "sql.NullTime",
+}
+
 type kind int
 
 const (
@@ -73,80 +154,159 @@ func basicKind(v reflect.Value) (kind, error) {
 	return invalidKind, errBadComparisonType
 }
 
-// eq evaluates the comparison a == b || a == c || ...
-func eq(arg1 interface{}, arg2 ...interface{}) (bool, error) {
-	v1 := reflect.ValueOf(arg1)
+// indirect dereferences the pointers and interfaces of v. It reports
+// whether it ends at nil, which an untyped nil does too.
+func indirect(v reflect.Value) (reflect.Value, bool) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return v, true
+		}
+		v = v.Elem()
+	}
+	return v, !v.IsValid()
+}
+
+// compareFast compares ints and strings, most of the comparisons in the
+// templates, without reflection. It's false for the other types.
+func compareFast(arg1, arg2 interface{}) (int, bool) {
+	switch a := arg1.(type) {
+	case int:
+		if b, ok := arg2.(int); ok {
+			switch {
+			case a < b:
+				return -1, true
+			case a > b:
+				return 1, true
+			}
+			return 0, true
+		}
+	case string:
+		if b, ok := arg2.(string); ok {
+			return strings.Compare(a, b), true
+		}
+	}
+	return 0, false
+}
+
+// comparisonValues returns the dereferenced values of the arguments and
+// their kind. Signed and unsigned integers are compared as integerKind,
+// since the constants in templates are always signed.
+func comparisonValues(arg1, arg2 interface{}) (reflect.Value, reflect.Value, kind, error) {
+	v1, _ := indirect(reflect.ValueOf(arg1))
+	v2, _ := indirect(reflect.ValueOf(arg2))
 	k1, err := basicKind(v1)
 	if err != nil {
-		return false, err
+		return v1, v2, invalidKind, err
+	}
+	k2, err := basicKind(v2)
+	if err != nil {
+		return v1, v2, invalidKind, err
+	}
+	if k1 != k2 {
+		if (k1 == intKind || k1 == uintKind) && (k2 == intKind || k2 == uintKind) {
+			return v1, v2, integerKind, nil
+		}
+		return v1, v2, invalidKind, errBadComparison
+	}
+	return v1, v2, k1, nil
+}
+
+// compareIntegers compares a signed and an unsigned integer, or two of the
+// same signedness.
+func compareIntegers(v1, v2 reflect.Value) int {
+	switch {
+	case v1.Kind() >= reflect.Uint && v1.Kind() <= reflect.Uintptr:
+		return -compareIntegers(v2, v1)
+	case v2.Kind() >= reflect.Uint && v2.Kind() <= reflect.Uintptr:
+		if v1.Int() < 0 || uint64(v1.Int()) < v2.Uint() {
+			return -1
+		} else if uint64(v1.Int()) > v2.Uint() {
+			return 1
+		}
+		return 0
+	case v1.Int() < v2.Int():
+		return -1
+	case v1.Int() > v2.Int():
+		return 1
 	}
+	return 0
+}
+
+// eq evaluates the comparison a == b || a == c || ...
+func eq(arg1 interface{}, arg2 ...interface{}) (bool, error) {
 	if len(arg2) == 0 {
 		return false, errNoComparison
 	}
 	for _, arg := range arg2 {
-		v2 := reflect.ValueOf(arg)
-		k2, err := basicKind(v2)
-		if err != nil {
-			return false, err
-		}
-		if k1 != k2 {
-			return false, errBadComparison
-		}
-		truth := false
-		switch k1 {
-		case boolKind:
-			truth = v1.Bool() == v2.Bool()
-		case complexKind:
-			truth = v1.Complex() == v2.Complex()
-		case floatKind:
-			truth = v1.Float() == v2.Float()
-		case intKind:
-			truth = v1.Int() == v2.Int()
-		case stringKind:
-			truth = v1.String() == v2.String()
-		case uintKind:
-			truth = v1.Uint() == v2.Uint()
-		default:
-			panic("invalid kind")
-		}
-		if truth {
-			return true, nil
+		truth, err := equal(arg1, arg)
+		if truth || err != nil {
+			return truth, err
 		}
 	}
 	return false, nil
 }
 
-// lt evaluates the comparison a < b.
-func lt(arg1, arg2 interface{}) (bool, error) {
-	v1 := reflect.ValueOf(arg1)
-	k1, err := basicKind(v1)
+// equal evaluates the comparison a == b. nil, typed or not, only equals
+// nil.
+func equal(arg1, arg2 interface{}) (bool, error) {
+	if c, ok := compareFast(arg1, arg2); ok {
+		return c == 0, nil
+	}
+
+	_, nil1 := indirect(reflect.ValueOf(arg1))
+	_, nil2 := indirect(reflect.ValueOf(arg2))
+	if nil1 || nil2 {
+		return nil1 && nil2, nil
+	}
+
+	v1, v2, k, err := comparisonValues(arg1, arg2)
 	if err != nil {
 		return false, err
 	}
-	v2 := reflect.ValueOf(arg2)
-	k2, err := basicKind(v2)
+	switch k {
+	case boolKind:
+		return v1.Bool() == v2.Bool(), nil
+	case complexKind:
+		return v1.Complex() == v2.Complex(), nil
+	case floatKind:
+		return v1.Float() == v2.Float(), nil
+	case intKind:
+		return v1.Int() == v2.Int(), nil
+	case integerKind:
+		return compareIntegers(v1, v2) == 0, nil
+	case stringKind:
+		return v1.String() == v2.String(), nil
+	case uintKind:
+		return v1.Uint() == v2.Uint(), nil
+	}
+	// slices are not comparable
+	return false, errBadComparisonType
+}
+
+// lt evaluates the comparison a < b.
+func lt(arg1, arg2 interface{}) (bool, error) {
+	if c, ok := compareFast(arg1, arg2); ok {
+		return c < 0, nil
+	}
+
+	v1, v2, k, err := comparisonValues(arg1, arg2)
 	if err != nil {
 		return false, err
 	}
-	if k1 != k2 {
-		return false, errBadComparison
-	}
-	truth := false
-	switch k1 {
-	case boolKind, complexKind:
-		return false, errBadComparisonType
+	switch k {
 	case floatKind:
-		truth = v1.Float() < v2.Float()
+		return v1.Float() < v2.Float(), nil
 	case intKind:
-		truth = v1.Int() < v2.Int()
+		return v1.Int() < v2.Int(), nil
+	case integerKind:
+		return compareIntegers(v1, v2) < 0, nil
 	case stringKind:
-		truth = v1.String() < v2.String()
+		return v1.String() < v2.String(), nil
 	case uintKind:
-		truth = v1.Uint() < v2.Uint()
-	default:
-		panic("invalid kind")
+		return v1.Uint() < v2.Uint(), nil
 	}
-	return truth, nil
+	// bools, complex numbers, slices and nil are not ordered
+	return false, errBadComparisonType
 }
 
 // le evaluates the comparison <= b.
@@ -169,48 +329,1017 @@ func gt(arg1, arg2 interface{}) (bool, error) {
 	return !lessOrEqual, nil
 }
 
+// ge evaluates the comparison a >= b.
+func ge(arg1, arg2 interface{}) (bool, error) {
+	// >= is the inverse of <.
+	lessThan, err := lt(arg1, arg2)
+	if err != nil {
+		return false, err
+	}
+	return !lessThan, nil
+}
+
+// ne evaluates the comparison a != b.
+func ne(arg1, arg2 interface{}) (bool, error) {
+	// != is the inverse of ==.
+	equal, err := eq(arg1, arg2)
+	if err != nil {
+		return false, err
+	}
+	return !equal, nil
+}
+
 func getCol(cols map[string]*core.Column, name string) *core.Column {
 	return cols[strings.ToLower(name)]
 }
 
+// hasCol reports whether the columns have one with the name.
+func hasCol(cols map[string]*core.Column, name string) bool {
+	return getCol(cols, name) != nil
+}
+
+// colOr returns the column with the name, or def when there is none.
+func colOr(cols map[string]*core.Column, name string, def *core.Column) *core.Column {
+	if col := getCol(cols, name); col != nil {
+		return col
+	}
+	return def
+}
+
 func formatGo(src string) (string, error) {
-	source, err := format.Source([]byte(src))
+	var source []byte
+	var err error
+	if useGoimports {
+		source, err = imports.Process("", []byte(src), nil)
+	} else {
+		source, err = format.Source([]byte(src))
+	}
 	if err != nil {
-		return "", err
+		return "", sourceError(src, err)
 	}
 	return string(source), nil
 }
 
+// typeDeclReg matches the lines declaring a type, e.g. type User struct {.
+var typeDeclReg = regexp.MustCompile(`^type\s+(\w+)\s`)
+
+// sourceError adds the lines of src around the position of a syntax error
+// to it, and the type being declared there, usually the struct of a table.
+func sourceError(src string, err error) error {
+	list, ok := err.(scanner.ErrorList)
+	if !ok || len(list) == 0 {
+		return err
+	}
+
+	lines := strings.Split(src, "\n")
+	line := list[0].Pos.Line
+	var buf bytes.Buffer
+	buf.WriteString(err.Error())
+	for i := line - 1; i >= 0 && i < len(lines); i-- {
+		if m := typeDeclReg.FindStringSubmatch(lines[i]); m != nil {
+			fmt.Fprintf(&buf, " in type %s", m[1])
+			break
+		}
+	}
+	for i := line - 3; i <= line+1; i++ {
+		if i < 0 || i >= len(lines) {
+			continue
+		}
+		marker := "  "
+		if i+1 == line {
+			marker = "> "
+		}
+		fmt.Fprintf(&buf, "\n%s%4d| %s", marker, i+1, lines[i])
+	}
+	return errors.New(buf.String())
+}
+
+// importGroups returns the sorted import specs of the standard library,
+// followed by the sorted third party ones, so the generated import blocks
+// are stable. Aliased imports are prefixed by their aliases.
+func importGroups(imports map[string]string) [][]string {
+	var std, others []string
+	for _, imp := range imports {
+		if strings.Contains(strings.SplitN(imp, "/", 2)[0], ".") {
+			others = append(others, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	std, others = distinct(std), distinct(others)
+	sort.Strings(std)
+	sort.Strings(others)
+
+	var res [][]string
+	for _, group := range [][]string{std, others} {
+		if len(group) == 0 {
+			continue
+		}
+		specs := make([]string, len(group))
+		for i, imp := range group {
+			specs[i] = strconv.Quote(imp)
+			if alias, ok := goImportAliases[imp]; ok {
+				specs[i] = alias + " " + specs[i]
+			}
+		}
+		res = append(res, specs)
+	}
+	return res
+}
+
+// goImportPaths maps the package qualifiers of the generated Go types to
+// their import paths.
+var goImportPaths = map[string]string{
+	"time":    "time",
+	"sql":     "database/sql",
+	"json":    "encoding/json",
+	"net":     "net",
+	"netip":   "net/netip",
+	"url":     "net/url",
+	"big":     "math/big",
+	"decimal": "github.com/shopspring/decimal",
+	"uuid":    "github.com/google/uuid",
+}
+
+// qualifierReg matches the package qualifiers of a Go type, e.g. sql in
+// *sql.NullString.
+var qualifierReg = regexp.MustCompile(`(?:^|[^\w.])([A-Za-z_]\w*)\.\w`)
+
 func genGoImports(tables []*core.Table) map[string]string {
 	imports := make(map[string]string)
 
 	for _, table := range tables {
 		for _, col := range table.Columns() {
-			if typestring(col) == "time.Time" {
-				imports["time"] = "time"
+			// the fields of BaseModel are in the file of BaseModel, their
+			// types only matter to the methods rendering them
+			if isBaseColumn(table, col) && !rendersBaseColumn(col) {
+				continue
+			}
+
+			// the package of a custom type takes precedence over the
+			// registry for its qualifier
+			var overridden string
+			if _, pkg, ok := overrideType(col); ok && pkg != "" {
+				imports[pkg] = pkg
+				overridden = path.Base(pkg)
+			}
+
+			for _, m := range qualifierReg.FindAllStringSubmatch(goTypeString(col), -1) {
+				if m[1] == overridden {
+					continue
+				}
+				if pkg, ok := importPath(m[1]); ok {
+					imports[pkg] = pkg
+				}
+			}
+		}
+		if len(enums(table)) > 0 {
+			for pkg := range enumImports() {
+				imports[pkg] = pkg
+			}
+		}
+		if len(sets(table)) > 0 {
+			for pkg := range setImports() {
+				imports[pkg] = pkg
 			}
 		}
 	}
 	return imports
 }
 
+// rendersBaseColumn reports whether the type of a column of BaseModel is
+// rendered by the file of a table embedding it, which is the case for the
+// insert struct, the accessors, the OrEmpty methods of the pointers, and
+// the primary key parameters of the repository and the slice methods.
+func rendersBaseColumn(col *core.Column) bool {
+	return (genInsertStruct && isInsertCol(col)) || genAccessors ||
+		(genOrEmpty && isPtr(typestring(col))) ||
+		((genRepository || genSliceTypes) && col.IsPrimaryKey)
+}
+
+// goImportAliases maps the import paths sharing their base name with
+// another import to the aliases they are imported as. They're set once for
+// the imports of all the files, so a package is aliased alike in all of
+// them and the files can be generated in any order.
+var goImportAliases = make(map[string]string)
+
+// aliasImports gives the imports sharing a base name the aliases base1,
+// base2 and so on, in the order of their paths. The packages of the
+// options qualified otherwise than by their base name are aliased by
+// their qualifier.
+func aliasImports(imports map[string]string) {
+	goImportAliases = make(map[string]string)
+
+	byBase := make(map[string][]string)
+	for pkg := range imports {
+		base := importName(pkg)
+		byBase[base] = append(byBase[base], pkg)
+	}
+	for base, pkgs := range byBase {
+		if len(pkgs) < 2 {
+			if base != path.Base(pkgs[0]) {
+				goImportAliases[pkgs[0]] = base
+			}
+			continue
+		}
+		sort.Strings(pkgs)
+		for i, pkg := range pkgs {
+			goImportAliases[pkg] = fmt.Sprintf("%s%d", base, i+1)
+		}
+	}
+}
+
+// typestring returns the Go type of the column with the qualifiers of the
+// aliased imports replaced by their aliases.
 func typestring(col *core.Column) string {
-	st := col.SQLType
-	t := core.SQLType2Type(st)
+	typ := goTypeString(col)
+	if len(goImportAliases) == 0 {
+		return typ
+	}
+
+	_, override, _ := overrideType(col)
+	return qualifierReg.ReplaceAllStringFunc(typ, func(m string) string {
+		q := qualifierReg.FindStringSubmatch(m)[1]
+		pkg, _ := importPath(q)
+		if override != "" && path.Base(override) == q {
+			pkg = override
+		}
+		if alias, ok := goImportAliases[pkg]; ok {
+			return strings.Replace(m, q+".", alias+".", 1)
+		}
+		return m
+	})
+}
+
+func goTypeString(col *core.Column) string {
+	if typ, _, ok := overrideType(col); ok {
+		return typ
+	}
+
+	s := sqlType2GoType(col.SQLType)
+	if u, ok := unsignedType(col); ok {
+		s = u
+	}
+	if isBool(col) {
+		s = "bool"
+	} else if strings.ToUpper(col.SQLType.Name) == core.Bit {
+		// BIT(n) is read as bytes
+		s = "[]byte"
+	} else if genBigInt && isBigInteger(col) {
+		s = "*big.Int"
+	} else if genEnumTypes && len(col.EnumOptions) > 0 {
+		s = enumTypeName(col)
+	} else if genSetSlice && len(col.SetOptions) > 0 {
+		// a nil slice already reads as NULL
+		return setTypeName(col)
+	}
+	if s == "" {
+		// the fallback is used as it is, nullable or not
+		return fallbackType(col)
+	}
+	if col.IsPrimaryKey || !col.Nullable {
+		return s
+	}
+	if genSqlNull {
+		if n, ok := sqlNullTypes[s]; ok {
+			return n
+		}
+	} else if genSqlNullGeneric && !strings.HasPrefix(s, "[]") && !strings.HasPrefix(s, "*") {
+		return "sql.Null[" + s + "]"
+	} else if genNullablePtr && col.Default == "" && !strings.HasPrefix(s, "[]") && !strings.HasPrefix(s, "*") {
+		// slices and pointers are left as is since a nil one already reads
+		// as NULL
+		return "*" + s
+	}
+	return s
+}
+
+// sqlType2GoType returns the Go type for a not null column of the SQL type.
+func sqlType2GoType(st core.SQLType) string {
+	if strings.HasSuffix(st.Name, "[]") {
+		elem := sqlType2GoType(core.SQLType{Name: strings.TrimSuffix(st.Name, "[]")})
+		if elem == "" {
+			return ""
+		}
+		if e, ok := arrayElemTypes[elem]; ok {
+			elem = e
+		}
+		return "[]" + elem
+	}
+	if decimalLib == "shopspring" && isDecimal(st) {
+		return "decimal.Decimal"
+	}
+	if genUuid && strings.ToUpper(st.Name) == core.Uuid {
+		return "uuid.UUID"
+	}
+	if genJsonRaw && isJson(st) {
+		return "json.RawMessage"
+	}
+	if geometryType != "" && isGeometry(st) {
+		return geometryType
+	}
+	switch strings.ToUpper(st.Name) {
+	case "YEAR":
+		return "int"
+	case core.Date:
+		if dateType != "" {
+			return dateType
+		}
+	case core.Time:
+		if timeOfDayType != "" {
+			return timeOfDayType
+		}
+	}
+
+	// core maps the types it doesn't know to string, which hides them
+	if _, ok := core.SqlTypes[strings.ToUpper(st.Name)]; !ok {
+		return ""
+	}
+	t := sqlType2Type(st)
+	if t == nil {
+		return ""
+	}
 	s := t.String()
-	if s == "[]uint8" {
+	switch s {
+	case "[]uint8":
 		return "[]byte"
+	case "time.Time":
+		if timeType != "" {
+			return timeType
+		}
 	}
 	return s
 }
 
-func tag(table *core.Table, col *core.Column) string {
-	// isNameId := (mapper.Table2Obj(col.Name) == "Id")
-	// isIdPk := isNameId && typestring(col) == "int64"
+// buildConstraint returns the //go:build line of the build expression and
+// the // +build lines of older Go, followed by the blank line separating
+// them from the package clause.
+func buildConstraint(expr string) (string, error) {
+	line := "//go:build " + strings.TrimSpace(expr)
+	x, err := constraint.Parse(line)
+	if err != nil {
+		return "", err
+	}
+	plus, err := constraint.PlusBuildLines(x)
+	if err != nil {
+		return "", err
+	}
+	return "//go:build " + x.String() + "\n" + strings.Join(plus, "\n") + "\n\n", nil
+}
 
-	var res []string
+// sqlTypes caches the results of core.SQLType2Type, which is asked for
+// every column by the imports, the tags and the fields. It's guarded by
+// sqlTypesMu since the templates are executed concurrently with -parallel.
+var (
+	sqlTypes   = make(map[core.SQLType]reflect.Type)
+	sqlTypesMu sync.RWMutex
+)
 
-	// SQLType
+// sqlType2Type returns core.SQLType2Type of the SQL type, computing it once
+// per type.
+func sqlType2Type(st core.SQLType) reflect.Type {
+	sqlTypesMu.RLock()
+	t, ok := sqlTypes[st]
+	sqlTypesMu.RUnlock()
+	if !ok {
+		t = core.SQLType2Type(st)
+		sqlTypesMu.Lock()
+		sqlTypes[st] = t
+		sqlTypesMu.Unlock()
+	}
+	return t
+}
+
+// warnedColumns are the columns of unknown SQL types which were already
+// warned about, guarded by warnedMu.
+var (
+	warnedColumns = make(map[*core.Column]bool)
+	warnedMu      sync.Mutex
+)
+
+// fallbackType returns the Go type of -unknown-type for a column whose SQL
+// type has no Go type, and warns about it once on stderr.
+func fallbackType(col *core.Column) string {
+	warnedMu.Lock()
+	defer warnedMu.Unlock()
+	if !warnedColumns[col] {
+		warnedColumns[col] = true
+		fmt.Fprintf(os.Stderr, "Warning: unknown SQL type %s of column %s.%s, using %s, add it to -type-map to override\n",
+			col.SQLType.Name, col.TableName, col.Name, unknownType)
+	}
+	return unknownType
+}
+
+// temporalGoTypes maps the short values of -date-type and
+// -time-of-day to Go types.
+var temporalGoTypes = map[string]string{
+	"time":     "time.Time",
+	"duration": "time.Duration",
+	"string":   "string",
+}
+
+// optionGoType returns the Go type of an option which is either one of
+// the short names or a type qualified by its import path. It's false when
+// the option is neither.
+func optionGoType(option string, short map[string]string) (string, bool) {
+	if option == "" {
+		return "", true
+	}
+	if t, ok := short[option]; ok {
+		return t, true
+	}
+	return qualifiedGoType(option)
+}
+
+// customImportPaths maps the qualifiers of the types of the options, like
+// -time-type, to their import paths. They're kept apart from
+// goImportPaths, so github.com/acme/time.Time doesn't take the qualifier
+// time from the standard library for the other columns.
+var customImportPaths = make(map[string]string)
+
+// importPath returns the import path of a package qualifier of a Go type.
+func importPath(q string) (string, bool) {
+	if pkg, ok := customImportPaths[q]; ok {
+		return pkg, true
+	}
+	pkg, ok := goImportPaths[q]
+	return pkg, ok
+}
+
+// importName returns the name a package is imported as, its qualifier in
+// customImportPaths or its base name.
+func importName(pkg string) string {
+	for q, p := range customImportPaths {
+		if p == pkg {
+			return q
+		}
+	}
+	return path.Base(pkg)
+}
+
+// qualifiedGoType returns the Go type of a type qualified by its import
+// path, registering the import for the generated code. A package whose
+// base name is the qualifier of another package is qualified by the
+// last two elements of its path instead, like acmetime for
+// github.com/acme/time, then by a number.
+func qualifiedGoType(t string) (string, bool) {
+	typ, pkg, err := splitQualifiedType(t)
+	if err != nil || pkg == "" {
+		return t, false
+	}
+
+	base := path.Base(pkg)
+	taken := func(q string) bool {
+		p, ok := importPath(q)
+		return ok && p != pkg
+	}
+	q := base
+	if taken(q) {
+		q = identifier(path.Base(path.Dir(pkg)) + base)
+		for n := 2; taken(q); n++ {
+			q = base + strconv.Itoa(n)
+		}
+		typ = strings.Replace(typ, base+".", q+".", 1)
+	}
+	customImportPaths[q] = pkg
+	return typ, true
+}
+
+// identifier drops the characters of s which can't be in a Go identifier.
+func identifier(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// unsignedColumns are the unsigned integer columns. core has no flag for
+// it, so the UNSIGNED modifier is moved from the SQL type name to here by
+// normalizeUnsigned, which keeps the names known to the type mappers.
+var unsignedColumns = make(map[*core.Column]bool)
+
+// normalizeUnsigned strips the UNSIGNED modifier from the SQL type names
+// of the columns and records it in unsignedColumns.
+func normalizeUnsigned(tables []*core.Table) {
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			name := strings.ToUpper(col.SQLType.Name)
+			if !strings.Contains(name, "UNSIGNED") {
+				continue
+			}
+			col.SQLType.Name = strings.Join(strings.Fields(strings.Replace(name, "UNSIGNED", "", -1)), " ")
+			unsignedColumns[col] = true
+		}
+	}
+}
+
+// unsignedType returns the Go type for an unsigned integer column such as
+// INT UNSIGNED.
+func unsignedType(col *core.Column) (string, bool) {
+	if !unsignedColumns[col] {
+		return "", false
+	}
+	u, ok := unsignedTypes[strings.ToUpper(col.SQLType.Name)]
+	return u, ok
+}
+
+func isInteger(st core.SQLType) bool {
+	switch sqlType2Type(st).Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// isBigInteger reports whether the column is a DECIMAL or NUMERIC integer
+// with more digits than an int64 holds.
+func isBigInteger(col *core.Column) bool {
+	return isDecimal(col.SQLType) && col.Length > 18 && col.Length2 == 0
+}
+
+// geometryGoTypes maps the short values of -geometry to the Go types of the
+// spatial columns.
+var geometryGoTypes = map[string]string{
+	"bytes":  "[]byte",
+	"string": "string",
+}
+
+// isGeometry reports whether the column is a spatial one of PostGIS or
+// MySQL.
+func isGeometry(st core.SQLType) bool {
+	switch strings.ToUpper(st.Name) {
+	case "GEOMETRY", "GEOGRAPHY", "POINT", "LINESTRING", "POLYGON", "MULTIPOINT",
+		"MULTILINESTRING", "MULTIPOLYGON", "GEOMETRYCOLLECTION":
+		return true
+	}
+	return false
+}
+
+func isJson(st core.SQLType) bool {
+	switch strings.ToUpper(st.Name) {
+	case core.Json, core.Jsonb:
+		return true
+	}
+	return false
+}
+
+func isDecimal(st core.SQLType) bool {
+	switch strings.ToUpper(st.Name) {
+	case core.Decimal, core.Numeric:
+		return true
+	}
+	return false
+}
+
+// sortedOptions returns the options of an ENUM or SET column in order.
+func sortedOptions(options map[string]int) []string {
+	opts := make([]string, 0, len(options))
+	for opt := range options {
+		opts = append(opts, opt)
+	}
+	sort.Strings(opts)
+	return opts
+}
+
+// GoEnum is the named string type generated for an ENUM column, or for the
+// elements of a SET column.
+type GoEnum struct {
+	Name   string
+	Values []GoEnumValue
+	// Table and Column are the names of the column in the database.
+	Table  string
+	Column string
+	// Scanner is set by -enum-scanner to generate Scan and Value methods
+	// accepting only the options.
+	Scanner bool
+}
+
+// GoEnumValue is a constant of a GoEnum.
+type GoEnumValue struct {
+	Name  string
+	Value string
+}
+
+// enums returns the named types of the table's ENUM columns, and of its
+// SET columns with -set-slice, when -enum-types is given. They are left to
+// the enums file with -enums-file.
+func enums(table *core.Table) []*GoEnum {
+	if !genEnumTypes || genEnumsFile {
+		return nil
+	}
+	return uniqueEnumValues(tableEnums(table))
+}
+
+// allEnums returns the named types of all the tables for the enums file,
+// grouped by table in order.
+func allEnums(tables []*core.Table) []*GoEnum {
+	if !genEnumTypes {
+		return nil
+	}
+
+	var res []*GoEnum
+	for _, table := range tables {
+		res = append(res, tableEnums(table)...)
+	}
+	return uniqueEnumValues(res)
+}
+
+// enumImports returns the imports of the methods of -enum-scanner.
+func enumImports() map[string]string {
+	if !genEnumScanner {
+		return nil
+	}
+	return map[string]string{"fmt": "fmt", "database/sql/driver": "database/sql/driver"}
+}
+
+// uniqueEnumValues numbers the constants whose names are taken by earlier
+// ones, like the options in-progress and in_progress which are both
+// InProgress, or by the types of nameEnums.
+func uniqueEnumValues(enums []*GoEnum) []*GoEnum {
+	seen := make(map[string]bool)
+	for name := range typeNames {
+		seen[name] = true
+	}
+	for _, e := range enums {
+		for i, v := range e.Values {
+			name := v.Name
+			for n := 2; seen[name]; n++ {
+				name = v.Name + strconv.Itoa(n)
+			}
+			seen[name] = true
+			e.Values[i].Name = name
+		}
+	}
+	return enums
+}
+
+func tableEnums(table *core.Table) []*GoEnum {
+	var res []*GoEnum
+	for _, col := range table.Columns() {
+		options := col.EnumOptions
+		if genSetSlice && len(col.SetOptions) > 0 {
+			options = col.SetOptions
+		}
+		if len(options) == 0 || isBaseColumn(table, col) {
+			continue
+		}
+		e := &GoEnum{Name: enumTypeName(col), Table: realTableName(table), Column: col.Name, Scanner: genEnumScanner}
+		for _, v := range sortedOptions(options) {
+			e.Values = append(e.Values, GoEnumValue{e.Name + enumValueName(v), v})
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+var (
+	// enumNames are the type names of the ENUM and SET columns given by
+	// nameEnums.
+	enumNames = make(map[*core.Column]string)
+	// typeNames are the struct and enum type names of the package, which
+	// the constants of the options don't take.
+	typeNames = make(map[string]bool)
+)
+
+// enumTypeName returns the type name of the ENUM or SET column, the one
+// given by nameEnums or the table and column names.
+func enumTypeName(col *core.Column) string {
+	if name, ok := enumNames[col]; ok {
+		return name
+	}
+	return goName(col.TableName) + goName(col.Name)
+}
+
+// GoSet is the slice type of a SET column with -set-slice, storing the
+// options joined by commas as the database does.
+type GoSet struct {
+	Name string
+	// Elem is the type of the options, the type of -enum-types or string.
+	Elem   string
+	Table  string
+	Column string
+}
+
+// sets returns the slice types of the table's SET columns with -set-slice.
+func sets(table *core.Table) []*GoSet {
+	if !genSetSlice {
+		return nil
+	}
+
+	var res []*GoSet
+	for _, col := range table.Columns() {
+		if len(col.SetOptions) == 0 || isBaseColumn(table, col) {
+			continue
+		}
+		elem := "string"
+		if genEnumTypes {
+			elem = enumTypeName(col)
+		}
+		res = append(res, &GoSet{Name: setTypeName(col), Elem: elem, Table: realTableName(table), Column: col.Name})
+	}
+	return res
+}
+
+// setImports returns the imports of the methods of the types of sets.
+func setImports() map[string]string {
+	return map[string]string{"fmt": "fmt", "strings": "strings", "database/sql/driver": "database/sql/driver"}
+}
+
+func setTypeName(col *core.Column) string {
+	return enumTypeName(col) + "Set"
+}
+
+func isPtr(typ string) bool {
+	return strings.HasPrefix(typ, "*")
+}
+
+// elemType returns the type a pointer type points to.
+func elemType(typ string) string {
+	return strings.TrimPrefix(typ, "*")
+}
+
+// maxNameLen returns the length of the longest field name of the table,
+// for templates padding the names.
+func maxNameLen(table *core.Table) int {
+	max := 0
+	for _, col := range table.Columns() {
+		if n := utf8.RuneCountInString(goName(col.Name)); n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+// colNames returns the column names of the table in the order of the
+// struct fields, the columns of an embedded BaseModel come first.
+func colNames(table *core.Table) []string {
+	var names []string
+	if embedsBase(table) {
+		names = append(names, baseModel.ColumnsSeq()...)
+	}
+	return distinct(append(names, table.ColumnsSeq()...))
+}
+
+// pkCols returns the primary key columns of the table in the order of the
+// key.
+func pkCols(table *core.Table) []*core.Column {
+	var cols []*core.Column
+	for _, name := range table.PrimaryKeys {
+		if col := table.GetColumn(name); col != nil {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// pkParams returns the parameters of the primary key of the table for
+// the methods of -repository, id for a single column and the column names
+// for a composite key.
+func pkParams(table *core.Table) string {
+	cols := pkCols(table)
+	if len(cols) == 1 {
+		return "id " + typestring(cols[0])
+	}
+
+	params := make([]string, 0, len(cols))
+	for _, col := range cols {
+		name := camelCase(col.Name)
+		if token.Lookup(name).IsKeyword() {
+			name += "_"
+		}
+		params = append(params, name+" "+typestring(col))
+	}
+	return strings.Join(params, ", ")
+}
+
+// sliceName returns the name of the slice type of the table's struct for
+// -slice-types, its plural, or the name followed by Slice when the struct
+// name is a plural already.
+func sliceName(table *core.Table) string {
+	name := typeName(table)
+	if p := plural(name); p != name && singular(name) == name {
+		return p
+	}
+	return name + "Slice"
+}
+
+// pluralName returns the plural of a Go name, e.g. the slice method of the
+// ID primary key is IDs rather than IDS.
+func pluralName(name string) string {
+	if name == strings.ToUpper(name) {
+		return name + "s"
+	}
+	return plural(name)
+}
+
+// autoIncrCol returns the auto increment column of the table. It's nil when
+// there is none, or more than one which can't be told apart.
+func autoIncrCol(table *core.Table) *core.Column {
+	var res *core.Column
+	for _, col := range table.Columns() {
+		if !isAutoIncr(col) {
+			continue
+		}
+		if res != nil {
+			return nil
+		}
+		res = col
+	}
+	return res
+}
+
+// fieldDoc returns the comment of the column as the doc comment of its
+// field when -doc-comment is given.
+func fieldDoc(col *core.Column) string {
+	if !genDocComment {
+		return ""
+	}
+	return docComment(col.Comment, "\t")
+}
+
+// fieldFacts returns a trailing comment of the field summarizing its
+// column, like // nullable, default 0, indexed, when -field-facts is given.
+func fieldFacts(col *core.Column) string {
+	if !genFieldFacts {
+		return ""
+	}
+
+	var facts []string
+	if col.IsPrimaryKey {
+		facts = append(facts, "primary key")
+	}
+	if isAutoIncr(col) {
+		facts = append(facts, "auto increment")
+	}
+	if col.Nullable && !col.IsPrimaryKey {
+		facts = append(facts, "nullable")
+	}
+	if col.Default != "" && !isSequenceDefault(col) {
+		facts = append(facts, "default "+defaultValue(col))
+	}
+	indexed := ""
+	for _, typ := range col.Indexes {
+		if typ == core.UniqueType {
+			indexed = "unique"
+		} else if indexed == "" {
+			indexed = "indexed"
+		}
+	}
+	if indexed != "" {
+		facts = append(facts, indexed)
+	}
+	if len(facts) == 0 {
+		return ""
+	}
+	// a default may span lines
+	return " // " + strings.Join(strings.Fields(strings.Join(facts, ", ")), " ")
+}
+
+// tableDoc returns the comment of the table as the doc comment of its
+// struct when -doc-comment is given.
+func tableDoc(table *core.Table) string {
+	if !genDocComment {
+		return ""
+	}
+	return docComment(table.Comment, "")
+}
+
+// docComment turns a comment into // lines with the indent, one for every
+// line of the comment. Control characters are dropped so the comment
+// can't break the generated code.
+func docComment(comment, indent string) string {
+	var res string
+	for _, line := range strings.Split(comment, "\n") {
+		line = strings.TrimSpace(strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) {
+				return -1
+			}
+			return r
+		}, line))
+		if line != "" {
+			res += indent + "// " + line + "\n"
+		}
+	}
+	return res
+}
+
+// realTableName returns the name of the table in the database, before the
+// prefix of the template config is stripped, qualified by its schema when
+// it's read by -schemas.
+func realTableName(table *core.Table) string {
+	name, ok := realTableNames[table]
+	if !ok {
+		name = table.Name
+	}
+	if schema := tableSchemas[table]; schema != "" {
+		return schema + "." + name
+	}
+	return name
+}
+
+// typeName returns the name of the struct generated for the table, wrapped
+// by -struct-prefix and -struct-suffix.
+func typeName(table *core.Table) string {
+	return structPrefix + goName(table.Name) + structSuffix
+}
+
+// goName maps a table or column name to a Go identifier, with acronyms
+// upper cased when -acronyms is given.
+func goName(name string) string {
+	name = mapper.Table2Obj(name)
+	if upperAcronymNames {
+		name = upperAcronyms(name)
+	}
+	return name
+}
+
+// nameEnums names the types of the ENUM and SET columns of the tables. A
+// name clashing with a struct, like UserStatus of the column status of
+// user and of the table user_status, or with an earlier enum gets the
+// suffix Enum, then a number. The names are kept for the constants of
+// the options too.
+func nameEnums(tables []*core.Table) {
+	enumNames = make(map[*core.Column]string)
+	typeNames = map[string]bool{goName(baseModelName): true}
+	for _, table := range tables {
+		if table != baseModel {
+			typeNames[typeName(table)] = true
+		}
+	}
+
+	// the slice type of a SET column is named after it too
+	taken := func(name string) bool {
+		return typeNames[name] || typeNames[name+"Set"]
+	}
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			if len(col.EnumOptions) == 0 && len(col.SetOptions) == 0 || isBaseColumn(table, col) {
+				continue
+			}
+			name := goName(col.TableName) + goName(col.Name)
+			if taken(name) {
+				base := name + "Enum"
+				name = base
+				for n := 2; taken(name); n++ {
+					name = base + strconv.Itoa(n)
+				}
+			}
+			enumNames[col] = name
+			typeNames[name] = true
+			typeNames[name+"Set"] = true
+		}
+	}
+}
+
+// enumValueName turns an enum option into a valid identifier suffix.
+func enumValueName(v string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, v)
+	name = mapper.Table2Obj(name)
+	if name == "" {
+		return "Empty"
+	}
+	return name
+}
+
+// checkIndexOrder returns the composite indexes of the table whose columns
+// are not in the order of the table's columns. xorm builds a composite
+// index from the struct fields in order, so these indexes would be created
+// with their columns reordered.
+func checkIndexOrder(table *core.Table) []*core.Index {
+	pos := make(map[string]int)
+	for i, name := range table.ColumnsSeq() {
+		pos[strings.ToLower(name)] = i
+	}
+
+	names := make([]string, 0, len(table.Indexes))
+	for name := range table.Indexes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []*core.Index
+	for _, name := range names {
+		index := table.Indexes[name]
+		for i := 1; i < len(index.Cols); i++ {
+			if pos[strings.ToLower(index.Cols[i-1])] > pos[strings.ToLower(index.Cols[i])] {
+				res = append(res, index)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// sqlTypeString returns the SQL type of the column with its length or
+// ENUM and SET options, e.g. VARCHAR(64).
+func sqlTypeString(col *core.Column) string {
 	nstr := col.SQLType.Name
 	if col.Length != 0 {
 		if col.Length2 != 0 {
@@ -222,13 +1351,7 @@ func tag(table *core.Table, col *core.Column) string {
 		nstr += "("
 		opts := ""
 
-		enumOptions := make([]string, 0, len(col.EnumOptions))
-		for enumOption := range col.EnumOptions {
-			enumOptions = append(enumOptions, enumOption)
-		}
-		sort.Strings(enumOptions)
-
-		for _, v := range enumOptions {
+		for _, v := range sortedOptions(col.EnumOptions) {
 			opts += fmt.Sprintf(",'%v'", v)
 		}
 		nstr += strings.TrimLeft(opts, ",")
@@ -237,18 +1360,37 @@ func tag(table *core.Table, col *core.Column) string {
 		nstr += "("
 		opts := ""
 
-		setOptions := make([]string, 0, len(col.SetOptions))
-		for setOption := range col.SetOptions {
-			setOptions = append(setOptions, setOption)
-		}
-		sort.Strings(setOptions)
-
-		for _, v := range setOptions {
+		for _, v := range sortedOptions(col.SetOptions) {
 			opts += fmt.Sprintf(",'%v'", v)
 		}
 		nstr += strings.TrimLeft(opts, ",")
 		nstr += ")"
 	}
+	if unsignedColumns[col] {
+		nstr += " UNSIGNED"
+	}
+	return nstr
+}
+
+func tag(table *core.Table, col *core.Column) string {
+	// isNameId := (mapper.Table2Obj(col.Name) == "Id")
+	// isIdPk := isNameId && typestring(col) == "int64"
+
+	var res []string
+
+	// the column name given by -explicit-colname, so xorm doesn't depend
+	// on the mapper to find the column
+	if explicitColName {
+		res = append(res, "'"+tagValue(col.Name)+"'")
+	}
+
+	// -> or <- given by -read-only and -write-only
+	if dir, ok := columnDirections[col]; ok {
+		res = append(res, dir)
+	}
+
+	// SQLType
+	nstr := sqlTypeString(col)
 	res = append(res, fmt.Sprintf("%-20s", nstr))
 
 	// IsPrimaryKey
@@ -260,15 +1402,16 @@ func tag(table *core.Table, col *core.Column) string {
 	res = append(res, fmt.Sprintf("%-4s", nstr))
 
 	// IsAutoIncrement
-	if col.IsAutoIncrement {
+	if isAutoIncr(col) {
 		nstr = "autoincr"
 	} else {
 		nstr = " "
 	}
 	res = append(res, fmt.Sprintf("%-10s", nstr))
 
-	// VERSION
-	if strings.ToUpper(col.Name) == "VERSION" {
+	// VERSION, a column named version is only an optimistic lock when it's
+	// an integer
+	if col.IsVersion || (strings.ToUpper(col.Name) == "VERSION" && isInteger(col.SQLType)) {
 		nstr = "version"
 	} else {
 		nstr = " "
@@ -287,20 +1430,18 @@ func tag(table *core.Table, col *core.Column) string {
 	}
 	res = append(res, fmt.Sprintf("%-10s", nstr))
 
-	// Default
-	if col.Default != "" {
-		colDefault := col.Default
-		if strings.Contains(colDefault, "character varying") {
-			colDefault = "''"
-		}
-		nstr = "default " + colDefault
+	// Default, a current time default becomes created or updated when
+	// -timestamp-tags is given
+	isNow, _ := timestampDefault(col)
+	if col.Default != "" && !isSequenceDefault(col) && !(genTimestampTags && isNow) {
+		nstr = "default " + tagValue(defaultValue(col))
 	} else {
 		nstr = " "
 	}
 	res = append(res, fmt.Sprintf("%-20s", nstr))
 
 	// created
-	if col.IsCreated {
+	if isCreated(col) {
 		nstr = "created"
 	} else {
 		nstr = " "
@@ -308,24 +1449,22 @@ func tag(table *core.Table, col *core.Column) string {
 	res = append(res, fmt.Sprintf("%-10s", nstr))
 
 	// updated
-	if col.IsUpdated {
+	if isUpdated(col) {
 		nstr = "updated"
 	} else {
 		nstr = " "
 	}
 	res = append(res, fmt.Sprintf("%-10s", nstr))
 
+	if isDeleted(col) {
+		res = append(res, fmt.Sprintf("%-10s", "deleted"))
+	}
+
 	// Indexes
-	if len(col.Indexes) == 0 {
+	if names := tagIndexNames(table, col); len(names) == 0 {
 		nstr = " "
 		res = append(res, fmt.Sprintf("%-20s", nstr))
 	} else {
-		names := make([]string, 0, len(col.Indexes))
-		for name := range col.Indexes {
-			names = append(names, name)
-		}
-		sort.Strings(names)
-
 		for _, name := range names {
 			index := table.Indexes[name]
 			var uistr string
@@ -334,7 +1473,10 @@ func tag(table *core.Table, col *core.Column) string {
 			} else if index.Type == core.IndexType {
 				uistr = "index"
 			}
-			if len(index.Cols) > 1 {
+			// xorm orders the columns of a composite index by the struct
+			// fields, see checkIndexOrder. Single column indexes are named
+			// by xorm unless -keep-index-names is given
+			if len(index.Cols) > 1 || keepIndexNames {
 				uistr += "(" + index.Name + ")"
 			}
 			res = append(res, fmt.Sprintf("%-20s", uistr))
@@ -343,28 +1485,221 @@ func tag(table *core.Table, col *core.Column) string {
 
 	// postgres did not suppoert
 	if supportComment && col.Comment != "" {
-		comment := fmt.Sprintf("      comment('%s')", col.Comment)
+		// quotes are doubled as in SQL so xorm keeps the comment in one token
+		comment := fmt.Sprintf("      comment('%s')", strings.Replace(tagValue(col.Comment), "'", "''", -1))
 		res = append(res, fmt.Sprintf("%20s", comment))
 	}
 
-	var tags []string
+	tags := make(map[string]string)
 	if genJson {
-		tags = append(tags, "json:\""+col.Name+"\"  ")
+		tags["json"] = jsonTag(col)
 	}
-	if len(res) > 0 {
-		tags = append(tags, "xorm:\""+strings.Join(res, " ")+"\"")
+	if genYamlTag {
+		tags["yaml"] = nameTag("yaml", yamlCase, col, false)
+	}
+	if genTomlTag {
+		tags["toml"] = nameTag("toml", tomlCase, col, false)
+	}
+	if genDbTag {
+		tags["db"] = "db:\"" + tagValue(col.Name) + "\""
+	}
+	if genGorm {
+		tags["gorm"] = gormTag(table, col)
+	} else if len(res) > 0 {
+		tags["xorm"] = "xorm:\"" + joinTokens(res) + "\""
 	}
 	if genComment {
-		tags = append(tags, "  comment:\""+col.Comment+"\"")
+		tags["comment"] = "comment:\"" + tagValue(col.Comment) + "\""
+	}
+	if genValidate {
+		if v := validateTag(col); v != "" {
+			tags["validate"] = v
+		}
+	}
+	if genGinBinding && isRequired(col) {
+		tags["binding"] = "binding:\"required\""
+	}
+
+	return joinTags(tags)
+}
+
+// isDeleted reports whether the column is a soft delete column, which is a
+// nullable time column named by -deleted-names when -soft-delete is given.
+func isDeleted(col *core.Column) bool {
+	return col.IsDeleted || (genSoftDelete && col.Nullable && col.SQLType.IsTime() &&
+		matchAny(deletedNames, col.Name))
+}
+
+// tagIndexNames returns the sorted names of the column's indexes put in its
+// tags, which are none with -no-index-tags and the unique ones only with
+// -unique-index-only. The names are sorted so the tags don't depend on the
+// map order, and the indexes the table doesn't have are left out.
+func tagIndexNames(table *core.Table, col *core.Column) []string {
+	if noIndexTags {
+		return nil
 	}
 
-	if len(tags) > 0 {
-		return "`" + strings.Join(tags, " ") + "`"
+	names := make([]string, 0, len(col.Indexes))
+	for name := range col.Indexes {
+		index, ok := table.Indexes[name]
+		if !ok || (uniqueIndexOnly && index.Type != core.UniqueType) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isCreated reports whether the column is tagged as created, a soft delete
+// column never is.
+func isCreated(col *core.Column) bool {
+	isNow, onUpdate := timestampDefault(col)
+	return !isDeleted(col) && (col.IsCreated || (genTimestampTags && isNow && !onUpdate) ||
+		namedTimestamp(col, createdNames))
+}
+
+// isUpdated reports whether the column is tagged as updated, a soft delete
+// column never is.
+func isUpdated(col *core.Column) bool {
+	_, onUpdate := timestampDefault(col)
+	return !isDeleted(col) && (col.IsUpdated || (genTimestampTags && onUpdate) ||
+		namedTimestamp(col, updatedNames))
+}
+
+// isInsertCol reports whether the column is set on insert, which all but
+// the auto increment, created and updated columns are.
+func isInsertCol(col *core.Column) bool {
+	return !isAutoIncr(col) && !isCreated(col) && !isUpdated(col)
+}
+
+// insertCols returns the columns of the table's insert struct.
+func insertCols(table *core.Table) []*core.Column {
+	var cols []*core.Column
+	for _, col := range table.Columns() {
+		if isInsertCol(col) {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
+
+// namedTimestamp reports whether the column is a time column named by the
+// patterns when -infer-timestamps is given.
+func namedTimestamp(col *core.Column, names []string) bool {
+	return genInferTimestamps && col.SQLType.IsTime() && matchAny(names, col.Name)
+}
+
+// tagNames is the default order of the struct tags.
+var tagNames = []string{"json", "yaml", "toml", "db", "xorm", "gorm", "comment", "validate", "binding"}
+
+func isTagName(name string) bool {
+	for _, n := range tagNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// joinTags joins the struct tags by name, in the order given by -tag-order
+// and then in the default order.
+func joinTags(tags map[string]string) string {
+	var res []string
+	for _, names := range [][]string{tagOrder, tagNames} {
+		for _, name := range names {
+			if t, ok := tags[name]; ok {
+				res = append(res, t)
+				delete(tags, name)
+			}
+		}
+	}
+
+	if len(res) > 0 {
+		return "`" + strings.Join(res, "   ") + "`"
+	}
+	return ""
+}
+
+// embedTag returns the tags of the embedded BaseModel field, which xorm
+// maps by extends and gorm by embedded.
+func embedTag() string {
+	tags := make(map[string]string)
+	if genGorm {
+		tags["gorm"] = `gorm:"embedded"`
 	} else {
+		tags["xorm"] = `xorm:"extends"`
+	}
+	return joinTags(tags)
+}
+
+// isRequired reports whether a value must be given for the column, which
+// is NOT NULL and neither a primary key nor has a default.
+func isRequired(col *core.Column) bool {
+	return !col.Nullable && !col.IsPrimaryKey && col.Default == ""
+}
+
+// validateTag returns the validate tag of go-playground/validator for the
+// column, required by isRequired and max for the length of text columns.
+func validateTag(col *core.Column) string {
+	var rules []string
+	if isRequired(col) {
+		rules = append(rules, "required")
+	}
+	if col.SQLType.IsText() && col.Length > 0 && len(col.EnumOptions) == 0 && len(col.SetOptions) == 0 {
+		rules = append(rules, fmt.Sprintf("max=%d", col.Length))
+	}
+	if len(rules) == 0 {
 		return ""
 	}
+	return "validate:\"" + strings.Join(rules, ",") + "\""
+}
+
+// jsonTag returns the json tag of the column.
+func jsonTag(col *core.Column) string {
+	omitEmpty := !col.IsPrimaryKey && (jsonOmitEmpty || (jsonOmitEmptyNullable && col.Nullable))
+	return nameTag("json", jsonCase, col, omitEmpty)
+}
+
+// nameTag returns a tag like json:"name" of an encoding naming the field
+// by the column name in the case, camel, snake or as it is when blank.
+func nameTag(key, nameCase string, col *core.Column, omitEmpty bool) string {
+	opts := toCase(col.Name, nameCase)
+	if omitEmpty {
+		opts += ",omitempty"
+	}
+	return key + ":\"" + tagValue(opts) + "\""
+}
+
+// joinTokens joins the padded tokens of an xorm tag with single spaces.
+// The placeholders of absent attributes are left out with -compact-tags
+// and -skip-empty-tokens, and -compact-tags trims the padding too.
+func joinTokens(tokens []string) string {
+	if !compactTags && !skipEmptyTokens {
+		return strings.Join(tokens, " ")
+	}
+
+	res := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		if strings.TrimSpace(t) == "" {
+			continue
+		}
+		if compactTags {
+			t = strings.TrimSpace(t)
+		}
+		res = append(res, t)
+	}
+	return strings.TrimRight(strings.Join(res, " "), " ")
 }
 
+// tagValue escapes s for a double quoted struct tag value. Backquotes are
+// replaced since the struct tag is a raw string literal.
+func tagValue(s string) string {
+	return tagValueReplacer.Replace(s)
+}
+
+var tagValueReplacer = strings.NewReplacer("`", "'", `\`, `\\`, `"`, `\"`, "\r", " ", "\n", " ")
+
 func distinct(input []string) []string {
 	u := make([]string, 0, len(input))
 	m := make(map[string]bool)
@@ -0,0 +1,1016 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+// newTable returns a table of the columns, with their table name set.
+func newTable(name string, cols ...*core.Column) *core.Table {
+	table := core.NewEmptyTable()
+	table.Name = name
+	for _, col := range cols {
+		col.TableName = name
+		if col.Indexes == nil {
+			col.Indexes = make(map[string]int)
+		}
+		table.AddColumn(col)
+	}
+	return table
+}
+
+func TestUnsignedTypes(t *testing.T) {
+	tests := []struct {
+		sqlType string
+		length  int
+		goType  string
+		xorm    string
+	}{
+		{"TINYINT UNSIGNED", 3, "uint8", "TINYINT(3) UNSIGNED"},
+		{"INT UNSIGNED", 10, "uint32", "INT(10) UNSIGNED"},
+		{"int unsigned", 0, "uint32", "INT UNSIGNED"},
+		{"BIGINT UNSIGNED", 20, "uint64", "BIGINT(20) UNSIGNED"},
+		{"BIGINT", 20, "int64", "BIGINT(20)"},
+	}
+	for _, test := range tests {
+		col := &core.Column{Name: "id", SQLType: core.SQLType{Name: test.sqlType}, Length: test.length}
+		normalizeUnsigned([]*core.Table{newTable("t", col)})
+		if got := typestring(col); got != test.goType {
+			t.Errorf("%s: typestring = %q, want %q", test.sqlType, got, test.goType)
+		}
+		if got := sqlTypeString(col); got != test.xorm {
+			t.Errorf("%s: sqlTypeString = %q, want %q", test.sqlType, got, test.xorm)
+		}
+	}
+}
+
+// setFlag sets a bool option for the test and restores it after.
+func setFlag(t *testing.T, p *bool, v bool) {
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+func TestEnumNameClashes(t *testing.T) {
+	setFlag(t, &genEnumTypes, true)
+	t.Cleanup(func() { nameEnums(nil) })
+
+	status := &core.Column{Name: "status", SQLType: core.SQLType{Name: core.Enum},
+		EnumOptions: map[string]int{"new": 0, "paid": 1}}
+	tbs := []*core.Table{
+		newTable("user",
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+			&core.Column{Name: "status", SQLType: core.SQLType{Name: core.Enum},
+				EnumOptions: map[string]int{"active": 0}}),
+		newTable("user_status",
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true}),
+		newTable("order",
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true}, status),
+		newTable("order_status_paid",
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true}),
+	}
+	nameEnums(tbs)
+
+	want := [][]string{{"UserStatusEnum", "UserStatusEnumActive"}, nil, {"OrderStatus", "OrderStatusNew", "OrderStatusPaid2"}, nil}
+	for i, table := range tbs {
+		var got []string
+		for _, e := range enums(table) {
+			got = append(got, e.Name)
+			for _, v := range e.Values {
+				got = append(got, v.Name)
+			}
+		}
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("%s: enums %v, want %v", table.Name, got, want[i])
+		}
+	}
+	if got := typestring(status); got != "OrderStatus" {
+		t.Errorf("type of status %s, want OrderStatus", got)
+	}
+
+	checkGo(t, renderGo(t, "goxorm", &Tmpl{Tables: tbs, Imports: genGoImports(tbs)}))
+}
+
+func TestBoolColumns(t *testing.T) {
+	tests := []struct {
+		col    *core.Column
+		typ    string
+		defTag string
+	}{
+		// postgres
+		{&core.Column{Name: "active", SQLType: core.SQLType{Name: "BOOLEAN"}, Default: "true"}, "bool", "default true"},
+		{&core.Column{Name: "active", SQLType: core.SQLType{Name: core.Bool}, Default: "false"}, "bool", "default false"},
+		// mysql
+		{&core.Column{Name: "active", SQLType: core.SQLType{Name: core.Bit}, Length: 1, Default: "b'1'"}, "bool", "default true"},
+		{&core.Column{Name: "flags", SQLType: core.SQLType{Name: core.Bit}, Length: 8}, "[]byte", ""},
+	}
+	for _, test := range tests {
+		table := newTable("user", test.col)
+		if got := typestring(test.col); got != test.typ {
+			t.Errorf("%s(%d) type %s, want %s", test.col.SQLType.Name, test.col.Length, got, test.typ)
+		}
+		got := tag(table, test.col)
+		if test.defTag != "" && !strings.Contains(got, test.defTag) {
+			t.Errorf("%s(%d) tag %s, want %s", test.col.SQLType.Name, test.col.Length, got, test.defTag)
+		}
+	}
+}
+
+func TestGinBindingTag(t *testing.T) {
+	setFlag(t, &genGinBinding, true)
+	setFlag(t, &genValidate, true)
+	setFlag(t, &genJson, true)
+
+	tests := []struct {
+		col      *core.Column
+		binding  string
+		validate string
+	}{
+		{&core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}, Length: 20}, "required", "required,max=20"},
+		{&core.Column{Name: "note", SQLType: core.SQLType{Name: core.Varchar}, Length: 20, Nullable: true}, "", "max=20"},
+		{&core.Column{Name: "kind", SQLType: core.SQLType{Name: core.Int}, Default: "0"}, "", ""},
+		{&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true}, "", ""},
+	}
+	for _, test := range tests {
+		got := tag(newTable("user", test.col), test.col)
+		tags := reflect.StructTag(strings.Trim(got, "`"))
+		if b := tags.Get("binding"); b != test.binding {
+			t.Errorf("%s: binding %q, want %q in %s", test.col.Name, b, test.binding, got)
+		}
+		if v := tags.Get("validate"); v != test.validate {
+			t.Errorf("%s: validate %q, want %q in %s", test.col.Name, v, test.validate, got)
+		}
+		if j := tags.Get("json"); j != test.col.Name {
+			t.Errorf("%s: json %q in %s", test.col.Name, j, got)
+		}
+	}
+}
+
+func TestTemporalTypes(t *testing.T) {
+	t.Cleanup(func() {
+		dateType, timeOfDayType = "", ""
+		customImportPaths = make(map[string]string)
+	})
+
+	tests := []struct {
+		dateType, timeOfDay string
+		year, date, time    string
+		imports             []string
+	}{
+		{"", "", "int", "time.Time", "time.Time", []string{"time"}},
+		{"string", "duration", "int", "string", "time.Duration", []string{"time"}},
+		{"cloud.google.com/go/civil.Date", "string", "int", "civil.Date", "string",
+			[]string{"cloud.google.com/go/civil"}},
+	}
+	for _, test := range tests {
+		var ok1, ok2 bool
+		dateType, ok1 = optionGoType(test.dateType, temporalGoTypes)
+		timeOfDayType, ok2 = optionGoType(test.timeOfDay, temporalGoTypes)
+		if !ok1 || !ok2 {
+			t.Fatalf("-date-type=%s -time-of-day=%s refused", test.dateType, test.timeOfDay)
+		}
+
+		year := &core.Column{Name: "year", SQLType: core.SQLType{Name: "YEAR"}}
+		date := &core.Column{Name: "date", SQLType: core.SQLType{Name: core.Date}}
+		clock := &core.Column{Name: "clock", SQLType: core.SQLType{Name: core.Time}}
+		got := []string{typestring(year), typestring(date), typestring(clock)}
+		if want := []string{test.year, test.date, test.time}; !reflect.DeepEqual(got, want) {
+			t.Errorf("-date-type=%s -time-of-day=%s: types %v, want %v", test.dateType, test.timeOfDay, got, want)
+		}
+
+		var imports []string
+		for pkg := range genGoImports([]*core.Table{newTable("event", year, date, clock)}) {
+			imports = append(imports, pkg)
+		}
+		if !reflect.DeepEqual(imports, test.imports) {
+			t.Errorf("-date-type=%s -time-of-day=%s: imports %v, want %v", test.dateType, test.timeOfDay, imports, test.imports)
+		}
+	}
+}
+
+func TestCustomQualifiers(t *testing.T) {
+	t.Cleanup(func() {
+		dateType, timeOfDayType = "", ""
+		customImportPaths = make(map[string]string)
+		aliasImports(nil)
+	})
+
+	var ok1, ok2 bool
+	dateType, ok1 = optionGoType("github.com/acme/time.Date", temporalGoTypes)
+	timeOfDayType, ok2 = optionGoType("github.com/other/acme/time.Clock", temporalGoTypes)
+	if !ok1 || !ok2 {
+		t.Fatal("custom types refused")
+	}
+
+	created := &core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}}
+	date := &core.Column{Name: "date", SQLType: core.SQLType{Name: core.Date}}
+	clock := &core.Column{Name: "clock", SQLType: core.SQLType{Name: core.Time}}
+	tbs := []*core.Table{newTable("event", created, date, clock)}
+	imports := genGoImports(tbs)
+	want := map[string]string{"time": "time", "github.com/acme/time": "github.com/acme/time",
+		"github.com/other/acme/time": "github.com/other/acme/time"}
+	if !reflect.DeepEqual(imports, want) {
+		t.Errorf("imports %v, want %v", imports, want)
+	}
+
+	aliasImports(imports)
+	got := []string{typestring(created), typestring(date), typestring(clock)}
+	if want := []string{"time.Time", "acmetime.Date", "time2.Clock"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("types %v, want %v", got, want)
+	}
+	aliases := map[string]string{"github.com/acme/time": "acmetime", "github.com/other/acme/time": "time2"}
+	if !reflect.DeepEqual(goImportAliases, aliases) {
+		t.Errorf("aliases %v, want %v", goImportAliases, aliases)
+	}
+}
+
+func TestSkipEmptyTokens(t *testing.T) {
+	col := &core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true, IsAutoIncrement: true}
+	table := newTable("t", col)
+	tests := []struct {
+		flag *bool
+		want string
+	}{
+		{&skipEmptyTokens, "`xorm:\"BIGINT               pk   autoincr\"`"},
+		{&compactTags, "`xorm:\"BIGINT pk autoincr\"`"},
+	}
+	for _, test := range tests {
+		setFlag(t, test.flag, true)
+		if got := tag(table, col); got != test.want {
+			t.Errorf("tag %q, want %q", got, test.want)
+		}
+		*test.flag = false
+	}
+}
+
+func TestCommentTags(t *testing.T) {
+	setFlag(t, &supportComment, true)
+	setFlag(t, &genComment, true)
+	setFlag(t, &compactTags, true)
+
+	tests := []struct {
+		comment string
+		xorm    string
+		tag     string
+	}{
+		{"it's a test", `comment('it''s a test')`, `comment:"it's a test"`},
+		{"a `quoted` name", `comment('a ''quoted'' name')`, `comment:"a 'quoted' name"`},
+		{`say "hi"`, `comment('say \"hi\"')`, `comment:"say \"hi\""`},
+	}
+	for _, test := range tests {
+		col := &core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}, Length: 20, Comment: test.comment}
+		got := tag(newTable("user", col), col)
+		if strings.Count(got, "`") != 2 {
+			t.Errorf("%q: tag %s isn't one literal", test.comment, got)
+		}
+		if !strings.Contains(got, test.xorm) {
+			t.Errorf("%q: tag %s, want it to contain %s", test.comment, got, test.xorm)
+		}
+		if !strings.Contains(got, test.tag) {
+			t.Errorf("%q: tag %s, want it to contain %s", test.comment, got, test.tag)
+		}
+	}
+}
+
+func TestJsonTagCamel(t *testing.T) {
+	old := jsonCase
+	jsonCase = "camel"
+	t.Cleanup(func() { jsonCase = old })
+
+	col := &core.Column{Name: "user_id", SQLType: core.SQLType{Name: core.BigInt}}
+	if got, want := jsonTag(col), `json:"userId"`; got != want {
+		t.Errorf("json tag %s, want %s", got, want)
+	}
+}
+
+func TestDbTag(t *testing.T) {
+	setFlag(t, &genJson, true)
+	setFlag(t, &genDbTag, true)
+	setFlag(t, &genComment, true)
+	setFlag(t, &compactTags, true)
+
+	col := &core.Column{Name: "user_id", SQLType: core.SQLType{Name: core.BigInt}, Comment: "the user"}
+	want := "`" + `json:"user_id"   db:"user_id"   xorm:"BIGINT not null"   comment:"the user"` + "`"
+	if got := tag(newTable("order", col), col); got != want {
+		t.Errorf("tag = %s, want %s", got, want)
+	}
+}
+
+// xormTag returns the options of the xorm tag of the column.
+func xormTag(table *core.Table, col *core.Column) []string {
+	tag := reflect.StructTag(strings.Trim(tag(table, col), "`"))
+	return strings.Fields(tag.Get("xorm"))
+}
+
+func TestDirectionTags(t *testing.T) {
+	id := &core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true}
+	secret := &core.Column{Name: "secret", SQLType: core.SQLType{Name: core.Varchar}, Length: 64}
+	table := newTable("user", id, secret)
+	old := columnDirections
+	columnDirections = map[*core.Column]string{id: "->", secret: "<-"}
+	t.Cleanup(func() { columnDirections = old })
+
+	tests := []struct {
+		col      *core.Column
+		explicit bool
+		xorm     string
+		gorm     string
+	}{
+		{id, false, `-> BIGINT pk`, `column:id;type:BIGINT;primaryKey;<-:false`},
+		{secret, false, `<- VARCHAR(64) not null`, `column:secret;type:VARCHAR(64);->:false;not null`},
+		{secret, true, `'secret' <- VARCHAR(64) not null`, `column:secret;type:VARCHAR(64);->:false;not null`},
+	}
+	for _, test := range tests {
+		setFlag(t, &explicitColName, test.explicit)
+		if got := strings.Join(xormTag(table, test.col), " "); got != test.xorm {
+			t.Errorf("%s: xorm tag %s, want %s", test.col.Name, got, test.xorm)
+		}
+		if got := reflect.StructTag(gormTag(table, test.col)).Get("gorm"); got != test.gorm {
+			t.Errorf("%s: gorm tag %s, want %s", test.col.Name, got, test.gorm)
+		}
+	}
+}
+
+// execGoTemplate executes the template text with the Go functions on data.
+func execGoTemplate(t *testing.T, text string, data interface{}) string {
+	tmpl, err := template.New("test").Funcs(GoLangTmpl.Funcs).Parse(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatalf("%s: %v", text, err)
+	}
+	return buf.String()
+}
+
+func TestComparisonFuncs(t *testing.T) {
+	col := &core.Column{Name: "name", Length: 20}
+	tests := []struct {
+		text, want string
+	}{
+		{`{{eq .Length 20}}`, "true"},
+		{`{{eq .Length 10 20}}`, "true"},
+		{`{{ne .Length 20}}`, "false"},
+		{`{{lt .Length 30}}`, "true"},
+		{`{{le .Length 20}}`, "true"},
+		{`{{gt .Length 20}}`, "false"},
+		{`{{ge .Length 20}}`, "true"},
+		{`{{ge .Length 21}}`, "false"},
+		{`{{lt .Name "other"}}`, "true"},
+		{`{{ge .Name "name"}}`, "true"},
+		{`{{ne .Name "id"}}`, "true"},
+		{`{{gt 1.5 1.25}}`, "true"},
+	}
+	for _, test := range tests {
+		if got := execGoTemplate(t, test.text, col); got != test.want {
+			t.Errorf("%s = %s, want %s", test.text, got, test.want)
+		}
+	}
+}
+
+func TestComparisonKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		fn     func(interface{}, interface{}) (bool, error)
+		a, b   interface{}
+		want   bool
+		wantOK bool
+	}{
+		{"int < uint", lt, -1, uint(1), true, true},
+		{"uint < int", lt, uint(2), 1, false, true},
+		{"int8 <= uint64", le, int8(3), uint64(3), true, true},
+		{"uint64 > int", gt, uint64(1 << 63), 1, true, true},
+		{"int32 < int64", lt, int32(1), int64(2), true, true},
+		{"slice < slice", lt, []int{1}, []int{2}, false, false},
+		{"slice <= slice", le, []string{"a"}, []string{"a"}, false, false},
+		{"slice > slice", gt, []int{2}, []int{1}, false, false},
+		{"bool < bool", lt, false, true, false, false},
+		{"int < string", lt, 1, "1", false, false},
+	}
+	for _, test := range tests {
+		got, err := test.fn(test.a, test.b)
+		if (err == nil) != test.wantOK {
+			t.Errorf("%s: error %v", test.name, err)
+		} else if got != test.want {
+			t.Errorf("%s = %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	if _, err := eq([]int{1}, []int{1}); err != errBadComparisonType {
+		t.Errorf("eq of slices: error %v, want %v", err, errBadComparisonType)
+	}
+	if ok, err := eq(uint8(3), 3); !ok || err != nil {
+		t.Errorf("eq(uint8(3), 3) = %v, %v", ok, err)
+	}
+}
+
+func BenchmarkComparisons(b *testing.B) {
+	n := 10
+	args := []struct {
+		name       string
+		arg1, arg2 interface{}
+	}{
+		{"int", 1, 2},
+		{"string", "varchar", "text"},
+		{"int64-uint", int64(1), uint(2)},
+		{"pointer", &n, 10},
+	}
+	for _, arg := range args {
+		b.Run(arg.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := lt(arg.arg1, arg.arg2); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := eq(arg.arg1, arg.arg2); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestColumnLookups(t *testing.T) {
+	deleted := &core.Column{Name: "deleted_at"}
+	def := &core.Column{Name: "default"}
+	cols := map[string]*core.Column{"deleted_at": deleted}
+
+	if !hasCol(cols, "deleted_at") || !hasCol(cols, "Deleted_At") {
+		t.Error("hasCol of deleted_at is false")
+	}
+	if hasCol(cols, "updated_at") {
+		t.Error("hasCol of updated_at is true")
+	}
+	if got := colOr(cols, "deleted_at", def); got != deleted {
+		t.Errorf("colOr of deleted_at = %v", got.Name)
+	}
+	if got := colOr(cols, "updated_at", def); got != def {
+		t.Errorf("colOr of updated_at = %v", got.Name)
+	}
+
+	got := execGoTemplate(t, `{{if hasCol . "deleted_at"}}deleted{{end}}{{(colOr . "x" (getCol . "deleted_at")).Name}}`, cols)
+	if got != "deleteddeleted_at" {
+		t.Errorf("template output %q", got)
+	}
+}
+
+func TestTypeMapImports(t *testing.T) {
+	oldTypeMap := typeMap
+	typeMap = &TypeMap{Columns: map[string]string{"doc": "json.RawMessage"}, Types: map[string]string{}}
+	t.Cleanup(func() { typeMap = oldTypeMap })
+
+	doc := &core.Column{Name: "doc", SQLType: core.SQLType{Name: "JSON"}}
+	imports := genGoImports([]*core.Table{newTable("page", doc)})
+	if want := map[string]string{"encoding/json": "encoding/json"}; !reflect.DeepEqual(imports, want) {
+		t.Errorf("imports %v, want %v", imports, want)
+	}
+	if got := typestring(doc); got != "json.RawMessage" {
+		t.Errorf("type of doc %s", got)
+	}
+}
+
+func TestImportAliases(t *testing.T) {
+	oldTypeMap := typeMap
+	typeMap = &TypeMap{
+		Columns: map[string]string{
+			"kind":  "github.com/acme/billing/types.Kind",
+			"state": "*github.com/acme/users/types.State",
+		},
+		Types: map[string]string{},
+	}
+	t.Cleanup(func() {
+		typeMap = oldTypeMap
+		goImportAliases = make(map[string]string)
+	})
+
+	kind := &core.Column{Name: "kind", SQLType: core.SQLType{Name: core.Varchar}}
+	state := &core.Column{Name: "state", SQLType: core.SQLType{Name: core.Varchar}}
+	account := newTable("account", kind, state)
+	imports := genGoImports([]*core.Table{account})
+	aliasImports(imports)
+
+	groups := importGroups(imports)
+	want := [][]string{{`types1 "github.com/acme/billing/types"`, `types2 "github.com/acme/users/types"`}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("imports %v, want %v", groups, want)
+	}
+	if got := typestring(kind); got != "types1.Kind" {
+		t.Errorf("type of kind %s, want types1.Kind", got)
+	}
+	if got := typestring(state); got != "*types2.State" {
+		t.Errorf("type of state %s, want *types2.State", got)
+	}
+
+	// the aliases are the ones of all the files in a file importing one of
+	// the packages
+	invoice := newTable("invoice", &core.Column{Name: "kind", SQLType: core.SQLType{Name: core.Varchar}})
+	aliasImports(genGoImports([]*core.Table{account, invoice}))
+	groups = importGroups(genGoImports([]*core.Table{invoice}))
+	want = [][]string{{`types1 "github.com/acme/billing/types"`}}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("imports of invoice %v, want %v", groups, want)
+	}
+}
+
+func TestSetSlice(t *testing.T) {
+	setFlag(t, &genSetSlice, true)
+	setFlag(t, &genNullablePtr, true)
+
+	for _, enumTypes := range []bool{false, true} {
+		setFlag(t, &genEnumTypes, enumTypes)
+		tags := &core.Column{Name: "tags", SQLType: core.SQLType{Name: "SET"}, Nullable: true,
+			SetOptions: map[string]int{"new": 0, "sale": 1, "gift": 2}}
+		tbs := []*core.Table{newTable("item",
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true}, tags)}
+
+		if got := typestring(tags); got != "ItemTagsSet" {
+			t.Errorf("type of tags %s, want ItemTagsSet", got)
+		}
+		elem := "string"
+		if enumTypes {
+			elem = "ItemTags"
+		}
+		want := &GoSet{Name: "ItemTagsSet", Elem: elem, Table: "item", Column: "tags"}
+		if got := sets(tbs[0]); len(got) != 1 || !reflect.DeepEqual(got[0], want) {
+			t.Errorf("sets %v, want %v", got, want)
+		}
+
+		for _, dir := range []string{"go", "goxorm", "gomeddler"} {
+			src := renderGo(t, dir, &Tmpl{Tables: tbs, Imports: genGoImports(tbs)})
+			checkGo(t, src)
+			if !strings.Contains(src, "func (s ItemTagsSet) ToDB() ([]byte, error)") {
+				t.Errorf("%s: ItemTagsSet doesn't implement core.Conversion\n%s", dir, src)
+			}
+		}
+	}
+
+	setFlag(t, &genEnumTypes, false)
+	tags := &core.Column{Name: "tags", SQLType: core.SQLType{Name: "SET"}, SetOptions: map[string]int{"new": 0, "sale": 1}}
+	tbs := []*core.Table{newTable("item", tags)}
+	src := renderGo(t, "goxorm", &Tmpl{Package: "main", Tables: tbs, Imports: genGoImports(tbs)})
+	out := runGo(t, src, `package main
+
+import "fmt"
+
+func main() {
+	var s, empty, null ItemTagsSet
+	fmt.Println(s.FromDB([]byte("new,sale")), len(s), s[0], s[1])
+	data, _ := s.ToDB()
+	fmt.Printf("%s\n", data)
+	fmt.Println(empty.Scan(""), len(empty), empty != nil, null.Scan(nil), null == nil)
+	v, _ := null.Value()
+	fmt.Println(v == nil)
+}
+`)
+	want := "<nil> 2 new sale\nnew,sale\n<nil> 0 true <nil> true\ntrue\n"
+	if out != want {
+		t.Errorf("output %q, want %q", out, want)
+	}
+}
+
+// indexedTable returns a table whose email column has a unique index of its
+// own and is the first column of a composite index.
+func indexedTable() (*core.Table, *core.Column) {
+	email := &core.Column{Name: "email", SQLType: core.SQLType{Name: core.Varchar}, Length: 50,
+		Indexes: map[string]int{"UQE_user_email": core.UniqueType, "IDX_user_email_name": core.IndexType}}
+	name := &core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}, Length: 20,
+		Indexes: map[string]int{"IDX_user_email_name": core.IndexType}}
+	table := newTable("user", email, name)
+
+	unique := core.NewIndex("UQE_user_email", core.UniqueType)
+	unique.AddColumn("email")
+	table.AddIndex(unique)
+	index := core.NewIndex("IDX_user_email_name", core.IndexType)
+	index.AddColumn("email", "name")
+	table.AddIndex(index)
+	return table, email
+}
+
+func TestIndexTagFilters(t *testing.T) {
+	tests := []struct {
+		flag *bool
+		want []string
+	}{
+		{new(bool), []string{"index(IDX_user_email_name)", "unique"}},
+		{&noIndexTags, nil},
+		{&uniqueIndexOnly, []string{"unique"}},
+	}
+	for _, test := range tests {
+		setFlag(t, test.flag, true)
+		table, email := indexedTable()
+		var got []string
+		for _, opt := range xormTag(table, email) {
+			if strings.HasPrefix(opt, "unique") || strings.HasPrefix(opt, "index") {
+				got = append(got, opt)
+			}
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("index options %v, want %v", got, test.want)
+		}
+		*test.flag = false
+	}
+}
+
+func TestIndexTagOrder(t *testing.T) {
+	table, email := indexedTable()
+	// an index the table doesn't have is left out
+	email.Indexes["IDX_user_gone"] = core.IndexType
+
+	want := tag(table, email)
+	for i := 0; i < 20; i++ {
+		if got := tag(table, email); got != want {
+			t.Fatalf("tag %s, then %s", want, got)
+		}
+	}
+	if strings.Contains(want, "IDX_user_gone") {
+		t.Errorf("tag %s has an index the table doesn't have", want)
+	}
+}
+
+func TestKeepIndexNames(t *testing.T) {
+	for _, keep := range []bool{false, true} {
+		setFlag(t, &keepIndexNames, keep)
+		table, email := indexedTable()
+		want := []string{"index(IDX_user_email_name)", "unique"}
+		if keep {
+			want[1] = "unique(UQE_user_email)"
+		}
+
+		got := xormTag(table, email)
+		if got = got[len(got)-2:]; !reflect.DeepEqual(got, want) {
+			t.Errorf("-keep-index-names=%v: index options %v, want %v", keep, got, want)
+		}
+	}
+}
+
+func TestOrEmpty(t *testing.T) {
+	setFlag(t, &genNullablePtr, true)
+
+	tbs := []*core.Table{newTable("item",
+		&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+		&core.Column{Name: "count", SQLType: core.SQLType{Name: core.Int}, Nullable: true},
+		&core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}, Length: 20, Nullable: true},
+		&core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}, Nullable: true},
+		&core.Column{Name: "active", SQLType: core.SQLType{Name: core.Bool}, Nullable: true})}
+
+	for _, dir := range []string{"go", "goxorm", "gomeddler"} {
+		src := renderGo(t, dir, &Tmpl{Tables: tbs, Imports: genGoImports(tbs), OrEmpty: true})
+		checkGo(t, src)
+		for _, method := range []string{"CountOrEmpty() (v int)", "NameOrEmpty() (v string)",
+			"CreatedOrEmpty() (v time.Time)", "ActiveOrEmpty() (v bool)"} {
+			if !strings.Contains(src, "func (m *Item) "+method) {
+				t.Errorf("%s: no method %s\n%s", dir, method, src)
+			}
+		}
+		if strings.Contains(src, "IdOrEmpty") {
+			t.Errorf("%s: OrEmpty method of a field which isn't a pointer\n%s", dir, src)
+		}
+	}
+}
+
+func TestMaxNameLen(t *testing.T) {
+	tests := []struct {
+		cols []string
+		want int
+	}{
+		{nil, 0},
+		{[]string{"id", "created_at"}, len("CreatedAt")},
+		// the names are counted in runes, not bytes
+		{[]string{"id", "größe_straße"}, len("GrößeStraße") - 3},
+	}
+	for _, test := range tests {
+		var cols []*core.Column
+		for _, name := range test.cols {
+			cols = append(cols, &core.Column{Name: name, SQLType: core.SQLType{Name: core.Int}})
+		}
+		if got := maxNameLen(newTable("t", cols...)); got != test.want {
+			t.Errorf("maxNameLen of %v = %d, want %d", test.cols, got, test.want)
+		}
+	}
+	got := execGoTemplate(t, `{{$n := maxNameLen .}}{{range .Columns}}{{printf "%-*s|" $n (Mapper .Name)}}{{end}}`,
+		newTable("t", &core.Column{Name: "id"}, &core.Column{Name: "name"}))
+	if got != "Id  |Name|" {
+		t.Errorf("padded names %q", got)
+	}
+}
+
+func TestEnumScanner(t *testing.T) {
+	setFlag(t, &genEnumTypes, true)
+	setFlag(t, &genEnumScanner, true)
+
+	tbs := []*core.Table{newTable("order",
+		&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+		&core.Column{Name: "status", SQLType: core.SQLType{Name: core.Enum},
+			EnumOptions: map[string]int{"new": 0, "paid": 1}})}
+	src := renderGo(t, "goxorm", &Tmpl{Package: "main", Tables: tbs, Imports: genGoImports(tbs)})
+	out := runGo(t, src, `package main
+
+import "fmt"
+
+func main() {
+	var s OrderStatus
+	fmt.Println(s.Scan("paid"), s, s.Scan([]byte("gone")), s)
+	_, err := OrderStatus("gone").Value()
+	fmt.Println(OrderStatusNew.Valid(), OrderStatus("gone").Valid(), err)
+}
+`)
+	want := "<nil> paid OrderStatus: invalid value \"gone\" paid\n" +
+		"true false OrderStatus: invalid value \"gone\"\n"
+	if out != want {
+		t.Errorf("output %q, want %q", out, want)
+	}
+}
+
+// runGo runs the generated code of package main with the main file and
+// returns the output. It's skipped without the go command.
+func runGo(t *testing.T, src, main string) string {
+	goCmd, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go command")
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{"models.go": src, "main.go": main,
+		"go.mod": "module models\n\ngo 1.16\n"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cmd := exec.Command(goCmd, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("%v: %s\n%s", err, out, src)
+	}
+	return string(out)
+}
+
+func TestFormatGoError(t *testing.T) {
+	src := "package models\n" +
+		"\n" +
+		"type User struct {\n" +
+		"\tId   int64\n" +
+		"\tName string `xorm:\"name\"\n" +
+		"}\n"
+	_, err := formatGo(src)
+	if err == nil {
+		t.Fatal("no error for an unterminated tag")
+	}
+	msg := err.Error()
+	for _, want := range []string{"in type User", "   3| type User struct {", ">    5| \tName string `xorm:\"name\""} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q, want it to contain %q", msg, want)
+		}
+	}
+
+	if _, err := formatGo("package models\n\ntype User struct{ Id int64 }\n"); err != nil {
+		t.Errorf("error %v for valid code", err)
+	}
+}
+
+func TestAutoIncrCol(t *testing.T) {
+	name := &core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}}
+	id := &core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsAutoIncrement: true}
+	serial := &core.Column{Name: "id", SQLType: core.SQLType{Name: core.Integer}, Default: "nextval('t_id_seq'::regclass)"}
+	seq := &core.Column{Name: "seq", SQLType: core.SQLType{Name: core.BigInt}, IsAutoIncrement: true}
+
+	tests := []struct {
+		cols []*core.Column
+		want *core.Column
+	}{
+		{[]*core.Column{name}, nil},
+		{[]*core.Column{id, name}, id},
+		{[]*core.Column{serial, name}, serial},
+		{[]*core.Column{id, seq}, nil},
+	}
+	for i, test := range tests {
+		if got := autoIncrCol(newTable("t", test.cols...)); got != test.want {
+			t.Errorf("%d: auto increment column %v, want %v", i, got, test.want)
+		}
+	}
+
+	got := execGoTemplate(t, `{{with autoIncrCol .}}{{.Name}}{{else}}none{{end}}`, newTable("t", serial, name))
+	if got != "id" {
+		t.Errorf("template output %q", got)
+	}
+}
+
+func TestSliceTypes(t *testing.T) {
+	tbs := []*core.Table{
+		newTable("user",
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+			&core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}}),
+		newTable("country",
+			&core.Column{Name: "code", SQLType: core.SQLType{Name: core.Char}, Length: 2, IsPrimaryKey: true}),
+		newTable("membership",
+			&core.Column{Name: "user_id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+			&core.Column{Name: "org_id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true}),
+	}
+	want := []string{
+		"type Users []User", "func (s Users) Ids() []int64",
+		"type Countries []Country", "func (s Countries) Codes() []string",
+		"type Memberships []Membership",
+	}
+
+	for _, dir := range []string{"go", "goxorm", "gomeddler"} {
+		src := renderGo(t, dir, &Tmpl{Tables: tbs, Imports: genGoImports(tbs), SliceTypes: true})
+		checkGo(t, src)
+		for _, w := range want {
+			if !strings.Contains(src, w) {
+				t.Errorf("%s: no %s\n%s", dir, w, src)
+			}
+		}
+		// a composite key has no method
+		if strings.Contains(src, "func (s Memberships)") {
+			t.Errorf("%s: method of a composite key\n%s", dir, src)
+		}
+	}
+}
+
+// BenchmarkSQLType2Type looks the types of a wide table up three times per
+// column, as the imports, the tags and the fields do, and reports the calls
+// of core.SQLType2Type with and without the cache.
+func BenchmarkSQLType2Type(b *testing.B) {
+	names := []string{core.BigInt, core.Int, core.Varchar, core.Text, core.DateTime, core.Decimal, core.Bool, core.Blob}
+	var types []core.SQLType
+	for i := 0; i < 200; i++ {
+		types = append(types, core.SQLType{Name: names[i%len(names)]})
+	}
+	lookups := 3 * len(types)
+
+	b.Run("uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for j := 0; j < lookups; j++ {
+				core.SQLType2Type(types[j%len(types)])
+			}
+		}
+		b.ReportMetric(float64(lookups), "calls/op")
+	})
+	b.Run("cached", func(b *testing.B) {
+		var calls int
+		for i := 0; i < b.N; i++ {
+			sqlTypesMu.Lock()
+			sqlTypes = make(map[core.SQLType]reflect.Type)
+			sqlTypesMu.Unlock()
+			for j := 0; j < lookups; j++ {
+				sqlType2Type(types[j%len(types)])
+			}
+			calls += len(sqlTypes)
+		}
+		b.ReportMetric(float64(calls)/float64(b.N), "calls/op")
+	})
+}
+
+func TestSQLType2TypeConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			st := core.SQLType{Name: core.Varchar, DefaultLength: i}
+			if got := sqlType2Type(st); got != core.SQLType2Type(st) {
+				t.Errorf("type of %v %v", st, got)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestBuildConstraint(t *testing.T) {
+	lines, err := buildConstraint(" mysql && !sqlite ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "//go:build mysql && !sqlite\n// +build mysql,!sqlite\n\n"
+	if lines != want {
+		t.Errorf("lines %q, want %q", lines, want)
+	}
+
+	src, err := formatGo(lines + generatedMarker + "\n\npackage models\n\ntype User struct{ Id int64 }\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(src, want+generatedMarker+"\n") {
+		t.Errorf("formatted source lost the constraint:\n%s", src)
+	}
+	ctx := build.Default
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "user.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, tags := range [][]string{{"mysql"}, {"mysql", "sqlite"}} {
+		ctx.BuildTags = tags
+		match, err := ctx.MatchFile(dir, "user.go")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := len(tags) == 1; match != want {
+			t.Errorf("tags %v: file matched %v, want %v", tags, match, want)
+		}
+	}
+
+	if _, err := buildConstraint("mysql &&"); err == nil {
+		t.Error("no error for an invalid expression")
+	}
+}
+
+// renderGo executes the struct template of the Go template dir on data and
+// returns the formatted output.
+func renderGo(t *testing.T, dir string, data *Tmpl) string {
+	bs, err := ioutil.ReadFile(filepath.Join("templates", dir, "struct.go.tpl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := template.New(dir).Funcs(GoLangTmpl.Funcs).Parse(string(bs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.Package == "" {
+		data.Package = "models"
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		t.Fatal(err)
+	}
+	src, err := formatGo(buf.String())
+	if err != nil {
+		t.Fatalf("%s: %v", dir, err)
+	}
+	return src
+}
+
+// checkGo type checks the sources as the files of one package.
+func checkGo(t *testing.T, srcs ...string) {
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for i, src := range srcs {
+		f, err := parser.ParseFile(fset, fmt.Sprintf("file%d.go", i), src, 0)
+		if err != nil {
+			t.Fatalf("%v\n%s", err, src)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	if _, err := conf.Check("models", fset, files, nil); err != nil {
+		t.Errorf("%v\n%s", err, strings.Join(srcs, "\n"))
+	}
+}
+
+func TestUnknownTypes(t *testing.T) {
+	old := unknownType
+	t.Cleanup(func() { unknownType = old })
+
+	for _, typ := range []string{"string", "interface{}"} {
+		unknownType = typ
+		for _, sqlType := range []string{"MONEY", "INET", "XML[]"} {
+			col := &core.Column{Name: "value", SQLType: core.SQLType{Name: sqlType}, Nullable: true}
+			if got := typestring(col); got != typ {
+				t.Errorf("-unknown-type=%s: type of %s %s", typ, sqlType, got)
+			}
+		}
+	}
+}
+
+func TestSqlNullGenericTypes(t *testing.T) {
+	setFlag(t, &genSqlNullGeneric, true)
+
+	created := &core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}, Nullable: true}
+	parent := &core.Column{Name: "parent_id", SQLType: core.SQLType{Name: core.BigInt}, Nullable: true}
+	id := &core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true}
+	tests := []struct {
+		col  *core.Column
+		want string
+	}{
+		{created, "sql.Null[time.Time]"},
+		{parent, "sql.Null[int64]"},
+		{id, "int64"},
+	}
+	for _, test := range tests {
+		if got := typestring(test.col); got != test.want {
+			t.Errorf("%s: type %s, want %s", test.col.Name, got, test.want)
+		}
+	}
+
+	tbs := []*core.Table{newTable("node", id, created, parent)}
+	checkGo(t, renderGo(t, "goxorm", &Tmpl{Tables: tbs, Imports: genGoImports(tbs)}))
+}
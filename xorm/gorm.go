@@ -0,0 +1,65 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/go-xorm/core"
+)
+
+// gormTag returns the gorm tag of the column, e.g.
+// gorm:"column:id;type:BIGINT;primaryKey;autoIncrement".
+func gormTag(table *core.Table, col *core.Column) string {
+	res := []string{
+		"column:" + col.Name,
+		"type:" + sqlTypeString(col),
+	}
+
+	if col.IsPrimaryKey {
+		res = append(res, "primaryKey")
+	}
+	// -read-only and -write-only take the permission gorm doesn't have
+	switch columnDirections[col] {
+	case "->":
+		res = append(res, "<-:false")
+	case "<-":
+		res = append(res, "->:false")
+	}
+	if isAutoIncr(col) {
+		res = append(res, "autoIncrement")
+	}
+	if !col.Nullable && !col.IsPrimaryKey {
+		res = append(res, "not null")
+	}
+	// a current time default becomes autoCreateTime or autoUpdateTime with
+	// -timestamp-tags, like in the xorm tag
+	isNow, _ := timestampDefault(col)
+	if col.Default != "" && !isSequenceDefault(col) && !(genTimestampTags && isNow) {
+		// gorm splits the settings at every ; which isn't escaped
+		res = append(res, "default:"+strings.Replace(defaultValue(col), ";", `\;`, -1))
+	}
+	if isCreated(col) {
+		res = append(res, "autoCreateTime")
+	}
+	if isUpdated(col) {
+		res = append(res, "autoUpdateTime")
+	}
+
+	for _, name := range tagIndexNames(table, col) {
+		index := table.Indexes[name]
+		if index.Type == core.UniqueType {
+			res = append(res, "uniqueIndex:"+index.Name)
+		} else {
+			res = append(res, "index:"+index.Name)
+		}
+	}
+
+	if col.Comment != "" {
+		res = append(res, "comment:"+strings.Replace(col.Comment, ";", ",", -1))
+	}
+
+	return "gorm:\"" + tagValue(strings.Join(res, ";")) + "\""
+}
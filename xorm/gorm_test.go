@@ -0,0 +1,76 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-xorm/core"
+)
+
+func TestGormTagDefault(t *testing.T) {
+	tests := []struct {
+		sqlType string
+		def     string
+		want    string
+	}{
+		{core.Varchar, "'a;b'", `column:name;type:VARCHAR(20);not null;default:'a\;b'`},
+		{core.Varchar, "'active'::character varying", `column:name;type:VARCHAR(20);not null;default:'active'`},
+		{core.Int, "0", `column:name;type:INT(20);not null;default:0`},
+	}
+	for _, test := range tests {
+		col := &core.Column{Name: "name", SQLType: core.SQLType{Name: test.sqlType}, Length: 20, Default: test.def}
+		table := newTable("user", col)
+		// the value of the tag as gorm reads it
+		got := reflect.StructTag(gormTag(table, col)).Get("gorm")
+		if got != test.want {
+			t.Errorf("%s: gorm tag %s, want %s", test.def, got, test.want)
+		}
+	}
+}
+
+func TestGormTagTimestamps(t *testing.T) {
+	setFlag(t, &genTimestampTags, true)
+
+	tests := []struct {
+		def  string
+		want string
+	}{
+		{"CURRENT_TIMESTAMP", `column:at;type:DATETIME;autoCreateTime`},
+		{"CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP", `column:at;type:DATETIME;autoUpdateTime`},
+		{"", `column:at;type:DATETIME`},
+	}
+	for _, test := range tests {
+		col := &core.Column{Name: "at", SQLType: core.SQLType{Name: core.DateTime}, Nullable: true, Default: test.def}
+		got := reflect.StructTag(gormTag(newTable("user", col), col)).Get("gorm")
+		if got != test.want {
+			t.Errorf("%q: gorm tag %s, want %s", test.def, got, test.want)
+		}
+	}
+}
+
+func TestGormTagInferTimestamps(t *testing.T) {
+	setFlag(t, &genInferTimestamps, true)
+	oldCreated, oldUpdated := createdNames, updatedNames
+	createdNames, updatedNames = []string{"created_at"}, []string{"updated_at"}
+	t.Cleanup(func() { createdNames, updatedNames = oldCreated, oldUpdated })
+
+	tests := []struct {
+		name, sqlType, want string
+	}{
+		{"created_at", core.DateTime, `column:created_at;type:DATETIME;autoCreateTime`},
+		{"updated_at", core.TimeStamp, `column:updated_at;type:TIMESTAMP;autoUpdateTime`},
+		// only time columns are inferred
+		{"created_at", core.BigInt, `column:created_at;type:BIGINT`},
+	}
+	for _, test := range tests {
+		col := &core.Column{Name: test.name, SQLType: core.SQLType{Name: test.sqlType}, Nullable: true}
+		got := reflect.StructTag(gormTag(newTable("user", col), col)).Get("gorm")
+		if got != test.want {
+			t.Errorf("%s %s: gorm tag %s, want %s", test.name, test.sqlType, got, test.want)
+		}
+	}
+}
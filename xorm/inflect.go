@@ -0,0 +1,149 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// uncountables have the same singular and plural form.
+var uncountables = map[string]bool{
+	"data":        true,
+	"equipment":   true,
+	"fish":        true,
+	"information": true,
+	"media":       true,
+	"metadata":    true,
+	"money":       true,
+	"news":        true,
+	"series":      true,
+	"sheep":       true,
+	"species":     true,
+}
+
+// irregulars maps the singular words which don't follow the suffix rules to
+// their plurals.
+var irregulars = map[string]string{
+	"child":  "children",
+	"cookie": "cookies",
+	"foot":   "feet",
+	"goose":  "geese",
+	"half":   "halves",
+	"knife":  "knives",
+	"leaf":   "leaves",
+	"life":   "lives",
+	"man":    "men",
+	"mouse":  "mice",
+	"movie":  "movies",
+	"ox":     "oxen",
+	"person": "people",
+	"tooth":  "teeth",
+	"wife":   "wives",
+	"woman":  "women",
+}
+
+var singulars = func() map[string]string {
+	m := make(map[string]string, len(irregulars))
+	for s, p := range irregulars {
+		m[p] = s
+	}
+	return m
+}()
+
+// plural returns the plural of the last word of a name, e.g. user_info
+// becomes user_infos and Category becomes Categories. A word which is a
+// plural already is kept, e.g. users stays users.
+func plural(src string) string {
+	return inflectLast(src, func(w string) string {
+		if _, ok := singulars[w]; ok {
+			return w
+		}
+		if s := singularWord(w); s != w && pluralWord(s) == w {
+			return w
+		}
+		return pluralWord(w)
+	})
+}
+
+// pluralWord returns the plural of a lower cased singular word.
+func pluralWord(w string) string {
+	if p, ok := irregulars[w]; ok {
+		return p
+	}
+	switch {
+	case strings.HasSuffix(w, "is") && len(w) > 2:
+		// analysis becomes analyses
+		return w[:len(w)-2] + "es"
+	case hasSuffixAny(w, "s", "x", "z", "ch", "sh"):
+		return w + "es"
+	case strings.HasSuffix(w, "y") && len(w) > 1 && !isVowel(w[len(w)-2]):
+		return w[:len(w)-1] + "ies"
+	}
+	return w + "s"
+}
+
+// singular returns the singular of the last word of a name, e.g. users
+// becomes user and people becomes person.
+func singular(src string) string {
+	return inflectLast(src, singularWord)
+}
+
+// singularWord returns the singular of a lower cased word, which is the
+// word itself when it's no plural.
+func singularWord(w string) string {
+	if s, ok := singulars[w]; ok {
+		return s
+	}
+	switch {
+	case strings.HasSuffix(w, "ies") && len(w) > 3:
+		return w[:len(w)-3] + "y"
+	case hasSuffixAny(w, "sses", "xes", "zes", "ches", "shes", "uses"):
+		return w[:len(w)-2]
+	case hasSuffixAny(w, "ss", "us", "is"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// inflectLast applies fn to the lower cased last word of src and puts the
+// result back with the case of the original word.
+func inflectLast(src string, fn func(string) string) string {
+	words := splitWords(src)
+	if len(words) == 0 {
+		return src
+	}
+	last := words[len(words)-1]
+	lower := strings.ToLower(last)
+	if uncountables[lower] {
+		return src
+	}
+
+	res := fn(lower)
+	switch {
+	case last == strings.ToUpper(last) && len(last) > 1:
+		res = strings.ToUpper(res)
+	case unicode.IsUpper([]rune(last)[0]):
+		res = capitalize(res)
+	}
+
+	i := strings.LastIndex(src, last)
+	return src[:i] + res + src[i+len(last):]
+}
+
+func hasSuffixAny(s string, suffixes ...string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isVowel(c byte) bool {
+	return strings.IndexByte("aeiou", c) >= 0
+}
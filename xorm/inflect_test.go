@@ -0,0 +1,59 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"user", "users"},
+		{"users", "users"},
+		{"person", "people"},
+		{"people", "people"},
+		{"category", "categories"},
+		{"categories", "categories"},
+		{"analysis", "analyses"},
+		{"status", "statuses"},
+		{"address", "addresses"},
+		{"box", "boxes"},
+		{"day", "days"},
+		{"child", "children"},
+		{"news", "news"},
+		{"user_info", "user_infos"},
+		{"UserCategory", "UserCategories"},
+		{"ID", "IDS"},
+	}
+	for _, test := range tests {
+		if got := plural(test.in); got != test.want {
+			t.Errorf("plural(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
+
+func TestSingular(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"users", "user"},
+		{"user", "user"},
+		{"people", "person"},
+		{"person", "person"},
+		{"categories", "category"},
+		{"statuses", "status"},
+		{"status", "status"},
+		{"boxes", "box"},
+		{"children", "child"},
+		{"series", "series"},
+		{"user_infos", "user_info"},
+		{"UserCategories", "UserCategory"},
+	}
+	for _, test := range tests {
+		if got := singular(test.in); got != test.want {
+			t.Errorf("singular(%q) = %q, want %q", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+var (
+	JavaTmpl LangTmpl = LangTmpl{
+		template.FuncMap{"Mapper": mapName,
+			"Type":    javaTypeStr,
+			"UnTitle": unTitle,
+		},
+		formatBraces,
+		genJavaImports,
+	}
+)
+
+// javaImports maps the Java types which need an import to their packages.
+var javaImports = map[string]string{
+	"BigDecimal":    "java.math.BigDecimal",
+	"LocalDate":     "java.time.LocalDate",
+	"LocalTime":     "java.time.LocalTime",
+	"LocalDateTime": "java.time.LocalDateTime",
+}
+
+func javaTypeStr(col *core.Column) string {
+	tp := col.SQLType
+	name := strings.ToUpper(tp.Name)
+	switch name {
+	case core.Bit, core.TinyInt, core.SmallInt, core.MediumInt, core.Int, core.Integer, core.Serial:
+		return "Integer"
+	case core.BigInt, core.BigSerial:
+		return "Long"
+	case core.Date:
+		return "LocalDate"
+	case core.Time:
+		return "LocalTime"
+	case core.DateTime, core.TimeStamp, core.TimeStampz:
+		return "LocalDateTime"
+	case core.Decimal, core.Numeric:
+		return "BigDecimal"
+	case core.Real, core.Float:
+		return "Float"
+	case core.Double:
+		return "Double"
+	case core.Binary, core.VarBinary, core.TinyBlob, core.Blob, core.MediumBlob, core.LongBlob, core.Bytea:
+		return "byte[]"
+	case core.Bool:
+		return "Boolean"
+	default:
+		return "String"
+	}
+}
+
+func genJavaImports(tables []*core.Table) map[string]string {
+	imports := make(map[string]string)
+
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			if imp, ok := javaImports[javaTypeStr(col)]; ok {
+				imports[imp] = imp
+			}
+		}
+	}
+	return imports
+}
+
+// formatBraces indents the source by its braces and drops repeated blank
+// lines.
+func formatBraces(src string) (string, error) {
+	var res []string
+	depth := 0
+	blank := false
+	for _, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank && len(res) > 0 {
+				res = append(res, "")
+			}
+			blank = true
+			continue
+		}
+		blank = false
+
+		if strings.HasPrefix(line, "}") {
+			if len(res) > 0 && res[len(res)-1] == "" {
+				res = res[:len(res)-1]
+			}
+			if depth > 0 {
+				depth--
+			}
+		}
+		res = append(res, strings.Repeat("    ", depth)+line)
+		depth += strings.Count(line, "{") - strings.Count(line, "}")
+		if strings.HasPrefix(line, "}") {
+			depth++
+		}
+		if depth < 0 {
+			depth = 0
+		}
+	}
+	return strings.TrimSpace(strings.Join(res, "\n")) + "\n", nil
+}
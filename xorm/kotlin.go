@@ -0,0 +1,100 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+var (
+	KotlinTmpl LangTmpl = LangTmpl{
+		template.FuncMap{"Mapper": mapName,
+			"Type":    kotlinTypeStr,
+			"UnTitle": unTitle,
+			"Ident":   kotlinIdent,
+		},
+		nil,
+		genKotlinImports,
+	}
+)
+
+// kotlinImports maps the Kotlin types which need an import to their
+// packages.
+var kotlinImports = map[string]string{
+	"BigDecimal":    "java.math.BigDecimal",
+	"LocalDate":     "java.time.LocalDate",
+	"LocalTime":     "java.time.LocalTime",
+	"LocalDateTime": "java.time.LocalDateTime",
+}
+
+// kotlinKeywords can only be used as identifiers when quoted by backticks.
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true,
+	"else": true, "false": true, "for": true, "fun": true, "if": true,
+	"in": true, "interface": true, "is": true, "null": true, "object": true,
+	"package": true, "return": true, "super": true, "this": true,
+	"throw": true, "true": true, "try": true, "typealias": true,
+	"typeof": true, "val": true, "var": true, "when": true, "while": true,
+}
+
+func kotlinTypeStr(col *core.Column) string {
+	s := kotlinType(col.SQLType)
+	if col.Nullable && !col.IsPrimaryKey {
+		return s + "?"
+	}
+	return s
+}
+
+func kotlinType(tp core.SQLType) string {
+	name := strings.ToUpper(tp.Name)
+	switch name {
+	case core.Bit, core.TinyInt, core.SmallInt, core.MediumInt, core.Int, core.Integer, core.Serial:
+		return "Int"
+	case core.BigInt, core.BigSerial:
+		return "Long"
+	case core.Date:
+		return "LocalDate"
+	case core.Time:
+		return "LocalTime"
+	case core.DateTime, core.TimeStamp, core.TimeStampz:
+		return "LocalDateTime"
+	case core.Decimal, core.Numeric:
+		return "BigDecimal"
+	case core.Real, core.Float:
+		return "Float"
+	case core.Double:
+		return "Double"
+	case core.Binary, core.VarBinary, core.TinyBlob, core.Blob, core.MediumBlob, core.LongBlob, core.Bytea:
+		return "ByteArray"
+	case core.Bool:
+		return "Boolean"
+	default:
+		return "String"
+	}
+}
+
+// kotlinIdent quotes the name by backticks when it's a keyword.
+func kotlinIdent(name string) string {
+	if kotlinKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+func genKotlinImports(tables []*core.Table) map[string]string {
+	imports := make(map[string]string)
+
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			if imp, ok := kotlinImports[kotlinType(col.SQLType)]; ok {
+				imports[imp] = imp
+			}
+		}
+	}
+	return imports
+}
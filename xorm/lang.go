@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/go-xorm/core"
 )
@@ -19,14 +20,33 @@ type LangTmpl struct {
 }
 
 var (
-	mapper    = &core.SnakeMapper{}
-	langTmpls = map[string]LangTmpl{
-		"go":   GoLangTmpl,
-		"c++":  CPlusTmpl,
-		"objc": ObjcTmpl,
+	mapper    core.IMapper = core.SnakeMapper{}
+	langTmpls              = map[string]LangTmpl{
+		"go":         GoLangTmpl,
+		"c++":        CPlusTmpl,
+		"objc":       ObjcTmpl,
+		"typescript": TypeScriptTmpl,
+		"java":       JavaTmpl,
+		"python":     PythonTmpl,
+		"proto":      ProtoTmpl,
+		"kotlin":     KotlinTmpl,
+		"rust":       RustTmpl,
+		"csharp":     CSharpTmpl,
 	}
 )
 
+// mappers are the name mappers selectable by -mapper.
+var mappers = map[string]core.IMapper{
+	"snake": core.SnakeMapper{},
+	"same":  core.SameMapper{},
+	"gonic": core.LintGonicMapper,
+}
+
+// mapName maps a table or column name by the mapper selected by -mapper.
+func mapName(name string) string {
+	return mapper.Table2Obj(name)
+}
+
 func loadConfig(f string) map[string]string {
 	bts, err := ioutil.ReadFile(f)
 	if err != nil {
@@ -55,3 +75,174 @@ func unTitle(src string) string {
 		return strings.ToLower(string(src[0])) + src[1:]
 	}
 }
+
+// splitWords splits a name into its words at underscores, dashes, spaces
+// and case changes, e.g. user_id, UserId and HTTPServer.
+func splitWords(src string) []string {
+	var words []string
+	for _, field := range strings.FieldsFunc(src, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		rs := []rune(field)
+		start := 0
+		for i := 1; i < len(rs); i++ {
+			if unicode.IsUpper(rs[i]) && (unicode.IsLower(rs[i-1]) ||
+				(i+1 < len(rs) && unicode.IsUpper(rs[i-1]) && unicode.IsLower(rs[i+1]) && !isPluralS(rs, i+1))) {
+				words = append(words, string(rs[start:i]))
+				start = i
+			}
+		}
+		words = append(words, splitAcronyms(string(rs[start:]))...)
+	}
+	return words
+}
+
+// acronyms are kept upper cased by pascalCase and camelCase.
+var acronyms = map[string]bool{
+	"ACL": true, "API": true, "ASCII": true, "CPU": true, "CSS": true,
+	"DNS": true, "EOF": true, "GUID": true, "HTML": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "QPS": true,
+	"RAM": true, "RPC": true, "SLA": true, "SMTP": true, "SQL": true,
+	"SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"UI": true, "UID": true, "UUID": true, "URI": true, "URL": true,
+	"UTF8": true, "VM": true, "XML": true, "XMPP": true, "XSRF": true,
+	"XSS": true,
+}
+
+// upperAcronyms upper cases the acronyms of a mapped name in place, e.g.
+// UserId becomes UserID and ApiUrl becomes APIURL.
+func upperAcronyms(name string) string {
+	res := name
+	pos := 0
+	for _, w := range splitWords(name) {
+		i := strings.Index(name[pos:], w)
+		if i < 0 {
+			continue
+		}
+		i += pos
+		if a, ok := acronymWord(w); ok {
+			res = res[:i] + a + res[i+len(w):]
+		}
+		pos = i + len(w)
+	}
+	return res
+}
+
+// loadAcronyms replaces the acronyms by the comma separated list, where
+// default stands for the built-in acronyms.
+func loadAcronyms(list string) {
+	m := make(map[string]bool)
+	for _, a := range strings.Split(list, ",") {
+		a = strings.ToUpper(strings.TrimSpace(a))
+		if a == "DEFAULT" {
+			for k := range acronyms {
+				m[k] = true
+			}
+		} else if a != "" {
+			m[a] = true
+		}
+	}
+	acronyms = m
+}
+
+// isPluralS reports whether rs[i] is the s of a plural acronym like URLs.
+func isPluralS(rs []rune, i int) bool {
+	return rs[i] == 's' && (i+1 == len(rs) || !unicode.IsLower(rs[i+1]))
+}
+
+// splitAcronyms splits an upper cased word made of acronyms only, e.g.
+// APIURL, into the acronyms. Other words are returned as they are.
+func splitAcronyms(word string) []string {
+	if word == "" || word != strings.ToUpper(word) || acronyms[word] {
+		return []string{word}
+	}
+	for i := len(word) - 1; i > 0; i-- {
+		if !acronyms[word[:i]] {
+			continue
+		}
+		if rest := splitAcronyms(word[i:]); acronyms[rest[0]] {
+			return append([]string{word[:i]}, rest...)
+		}
+	}
+	return []string{word}
+}
+
+// titleWord returns the word title cased, or upper cased by acronymWord.
+func titleWord(word string) string {
+	if a, ok := acronymWord(word); ok {
+		return a
+	}
+	return capitalize(strings.ToLower(word))
+}
+
+// acronymWord returns the upper cased word when it's an acronym or the
+// plural of an acronym.
+func acronymWord(word string) (string, bool) {
+	upper := strings.ToUpper(word)
+	if acronyms[upper] {
+		return upper, true
+	}
+	if n := len(upper) - 1; n > 0 && upper[n] == 'S' && acronyms[upper[:n]] {
+		return upper[:n] + "s", true
+	}
+	return "", false
+}
+
+// snakeCase converts a name to snake_case.
+func snakeCase(src string) string {
+	words := splitWords(src)
+	for i, w := range words {
+		words[i] = strings.ToLower(w)
+	}
+	return strings.Join(words, "_")
+}
+
+// pascalCase converts a name to PascalCase.
+func pascalCase(src string) string {
+	words := splitWords(src)
+	for i, w := range words {
+		words[i] = titleWord(w)
+	}
+	return strings.Join(words, "")
+}
+
+// camelCase converts a name to camelCase, a leading acronym is lower cased
+// as a whole, e.g. id_card becomes idCard.
+func camelCase(src string) string {
+	words := splitWords(src)
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+		} else {
+			words[i] = titleWord(w)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// lowerCamelCase converts a name to camelCase without upper casing the
+// acronyms, e.g. user_id becomes userId. It names the fields of the
+// encodings, where that's the common style, while camelCase names the Go
+// identifiers.
+func lowerCamelCase(src string) string {
+	words := splitWords(src)
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+		} else {
+			words[i] = capitalize(strings.ToLower(w))
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// toCase converts a name to the case, camel, snake or as it is when blank.
+func toCase(name, nameCase string) string {
+	switch nameCase {
+	case "snake":
+		return snakeCase(name)
+	case "camel":
+		return lowerCamelCase(name)
+	}
+	return name
+}
@@ -0,0 +1,53 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import "testing"
+
+func TestCaseConversions(t *testing.T) {
+	tests := []struct {
+		in, snake, camel, pascal string
+	}{
+		{"user_name", "user_name", "userName", "UserName"},
+		{"UserName", "user_name", "userName", "UserName"},
+		{"userName", "user_name", "userName", "UserName"},
+		{"id", "id", "id", "ID"},
+		{"user_id", "user_id", "userID", "UserID"},
+		{"UserID", "user_id", "userID", "UserID"},
+		{"api_url", "api_url", "apiURL", "APIURL"},
+		{"HTTPServer", "http_server", "httpServer", "HTTPServer"},
+		{"userIDs", "user_ids", "userIDs", "UserIDs"},
+		{"created-at time", "created_at_time", "createdAtTime", "CreatedAtTime"},
+	}
+	for _, test := range tests {
+		if got := snakeCase(test.in); got != test.snake {
+			t.Errorf("snake(%q) = %q, want %q", test.in, got, test.snake)
+		}
+		if got := camelCase(test.in); got != test.camel {
+			t.Errorf("camel(%q) = %q, want %q", test.in, got, test.camel)
+		}
+		if got := pascalCase(test.in); got != test.pascal {
+			t.Errorf("pascal(%q) = %q, want %q", test.in, got, test.pascal)
+		}
+	}
+}
+
+func TestTagCase(t *testing.T) {
+	tests := []struct {
+		in, nameCase, want string
+	}{
+		{"user_id", "camel", "userId"},
+		{"api_url", "camel", "apiUrl"},
+		{"UserID", "camel", "userId"},
+		{"created_at", "camel", "createdAt"},
+		{"UserID", "snake", "user_id"},
+		{"user_id", "", "user_id"},
+	}
+	for _, test := range tests {
+		if got := toCase(test.in, test.nameCase); got != test.want {
+			t.Errorf("toCase(%q, %q) = %q, want %q", test.in, test.nameCase, got, test.want)
+		}
+	}
+}
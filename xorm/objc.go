@@ -14,7 +14,7 @@ import (
 
 var (
 	ObjcTmpl LangTmpl = LangTmpl{
-		template.FuncMap{"Mapper": mapper.Table2Obj,
+		template.FuncMap{"Mapper": mapName,
 			"Type":    objcTypeStr,
 			"UnTitle": unTitle,
 		},
@@ -0,0 +1,75 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"sync"
+	"text/template"
+)
+
+// filesMu guards the counts of the written files, which are written
+// concurrently with -parallel.
+var filesMu sync.Mutex
+
+// genJob is a file to generate from a template.
+type genJob struct {
+	fileName string
+	data     *Tmpl
+}
+
+// genFiles executes the template, formats and writes the files by n
+// workers. The output of a template doesn't depend on the other files,
+// since the import aliases are the same for all of them. A failing file
+// doesn't stop the others, whatever n is. With -dry-run the files are
+// printed in the order of the jobs, and the error returned is the first
+// one in that order, so the output doesn't depend on the scheduling.
+func genFiles(tmpl *template.Template, formater func(string) (string, error), header string, jobs []genJob, n int) error {
+	errs := make([]error, len(jobs))
+	printed := make([]string, len(jobs))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				job := jobs[i]
+				source, err := execTemplate(tmpl, header, job.data)
+				if err != nil {
+					errs[i] = err
+					continue
+				}
+				source, formatErr := formatSource(formater, job.fileName, source)
+				if dryRun {
+					printed[i], errs[i] = source, formatErr
+					continue
+				}
+				if err := writeSource(job.fileName, source); err != nil {
+					errs[i] = err
+				} else {
+					errs[i] = formatErr
+				}
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for i, job := range jobs {
+		if dryRun && printed[i] != "" {
+			writeSource(job.fileName, printed[i])
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+// syntheticTables returns n tables of a few columns of the common types.
+func syntheticTables(n int) []*core.Table {
+	tables := make([]*core.Table, n)
+	for i := range tables {
+		tables[i] = newTable(fmt.Sprintf("table_%03d", i),
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true, IsAutoIncrement: true},
+			&core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}, Length: 64},
+			&core.Column{Name: "price", SQLType: core.SQLType{Name: core.Decimal}, Length: 10, Length2: 2},
+			&core.Column{Name: "note", SQLType: core.SQLType{Name: core.Text}, Nullable: true},
+			&core.Column{Name: "active", SQLType: core.SQLType{Name: core.Bool}, Default: "1"},
+			&core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}, Default: "CURRENT_TIMESTAMP"})
+	}
+	return tables
+}
+
+// genSynthetic generates the files of the tables in dir by n workers.
+func genSynthetic(tb testing.TB, tmpl *template.Template, tables []*core.Table, dir string, n int) {
+	jobs := make([]genJob, len(tables))
+	for i, table := range tables {
+		tbs := []*core.Table{table}
+		jobs[i] = genJob{filepath.Join(dir, table.Name+".go"), &Tmpl{Tables: tbs, Imports: genGoImports(tbs), Package: "models"}}
+	}
+	if err := genFiles(tmpl, formatGo, "", jobs, n); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+func goxormTemplate(tb testing.TB) *template.Template {
+	bs, err := ioutil.ReadFile(filepath.Join("templates", "goxorm", "struct.go.tpl"))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tmpl, err := template.New("goxorm").Funcs(GoLangTmpl.Funcs).Parse(string(bs))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	return tmpl
+}
+
+func TestGenFilesParallel(t *testing.T) {
+	tmpl := goxormTemplate(t)
+	tables := syntheticTables(50)
+	serial, parallel := t.TempDir(), t.TempDir()
+	genSynthetic(t, tmpl, tables, serial, 1)
+	genSynthetic(t, tmpl, tables, parallel, 8)
+
+	for _, table := range tables {
+		want, err := ioutil.ReadFile(filepath.Join(serial, table.Name+".go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ioutil.ReadFile(filepath.Join(parallel, table.Name+".go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != string(want) {
+			t.Errorf("%s differs with -parallel:\n%s\nwant:\n%s", table.Name, got, want)
+		}
+	}
+}
+
+// BenchmarkGenFiles generates the files of 500 tables by 1, 4 and 8
+// workers.
+func BenchmarkGenFiles(b *testing.B) {
+	tmpl := goxormTemplate(b)
+	tables := syntheticTables(500)
+	for _, n := range []int{1, 4, 8} {
+		b.Run(fmt.Sprintf("workers=%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				genSynthetic(b, tmpl, tables, b.TempDir(), n)
+			}
+		})
+	}
+}
+
+func TestGenFilesErrors(t *testing.T) {
+	// the second table fails to execute, the last one to format
+	tmpl := template.Must(template.New("fail").Parse(`{{range .Tables}}{{if eq .Name "table_001"}}{{template "missing"}}{{end}}` +
+		`package models{{if eq .Name "table_003"}} func{{end}}{{end}}`))
+	tables := syntheticTables(4)
+
+	for _, n := range []int{1, 4} {
+		dir := t.TempDir()
+		jobs := make([]genJob, len(tables))
+		for i, table := range tables {
+			jobs[i] = genJob{filepath.Join(dir, table.Name+".go"), &Tmpl{Tables: []*core.Table{table}}}
+		}
+		err := genFiles(tmpl, formatGo, "", jobs, n)
+		if err == nil || !strings.Contains(err.Error(), "missing") {
+			t.Errorf("-parallel=%d: error %v, want the one of table_001", n, err)
+		}
+
+		var names []string
+		files, _ := ioutil.ReadDir(dir)
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		// the source which can't be formatted is still written
+		if want := []string{"table_000.go", "table_002.go", "table_003.go"}; !reflect.DeepEqual(names, want) {
+			t.Errorf("-parallel=%d: files %v, want %v", n, names, want)
+		}
+	}
+}
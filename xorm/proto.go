@@ -0,0 +1,74 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+var (
+	ProtoTmpl LangTmpl = LangTmpl{
+		template.FuncMap{"Mapper": mapName,
+			"Type":    protoTypeStr,
+			"UnTitle": unTitle,
+			"Snake":   snakeCase,
+			"Inc":     inc,
+		},
+		nil,
+		genProtoImports,
+	}
+)
+
+func protoTypeStr(col *core.Column) string {
+	s := protoType(col.SQLType)
+	// message types have presence already
+	if col.Nullable && !col.IsPrimaryKey && !strings.Contains(s, ".") {
+		return "optional " + s
+	}
+	return s
+}
+
+func protoType(tp core.SQLType) string {
+	name := strings.ToUpper(tp.Name)
+	switch name {
+	case core.Bit, core.TinyInt, core.SmallInt, core.MediumInt, core.Int, core.Integer, core.Serial:
+		return "int32"
+	case core.BigInt, core.BigSerial:
+		return "int64"
+	case core.Date, core.DateTime, core.Time, core.TimeStamp, core.TimeStampz:
+		return "google.protobuf.Timestamp"
+	case core.Real, core.Float:
+		return "float"
+	case core.Double:
+		return "double"
+	case core.Binary, core.VarBinary, core.TinyBlob, core.Blob, core.MediumBlob, core.LongBlob, core.Bytea:
+		return "bytes"
+	case core.Bool:
+		return "bool"
+	default:
+		return "string"
+	}
+}
+
+func genProtoImports(tables []*core.Table) map[string]string {
+	imports := make(map[string]string)
+
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			if protoType(col.SQLType) == "google.protobuf.Timestamp" {
+				imports["google/protobuf/timestamp.proto"] = "google/protobuf/timestamp.proto"
+			}
+		}
+	}
+	return imports
+}
+
+// inc returns i+1, for numbering fields from one in templates.
+func inc(i int) int {
+	return i + 1
+}
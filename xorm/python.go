@@ -0,0 +1,81 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+var (
+	PythonTmpl LangTmpl = LangTmpl{
+		template.FuncMap{"Mapper": mapName,
+			"Type":    pythonTypeStr,
+			"UnTitle": unTitle,
+		},
+		nil,
+		genPythonImports,
+	}
+)
+
+// pythonImports maps the Python types which need an import to the import
+// statements.
+var pythonImports = map[string]string{
+	"date":     "from datetime import date",
+	"time":     "from datetime import time",
+	"datetime": "from datetime import datetime",
+	"Decimal":  "from decimal import Decimal",
+}
+
+func pythonTypeStr(col *core.Column) string {
+	s := pythonType(col.SQLType)
+	if col.Nullable && !col.IsPrimaryKey {
+		return "Optional[" + s + "]"
+	}
+	return s
+}
+
+func pythonType(tp core.SQLType) string {
+	name := strings.ToUpper(tp.Name)
+	switch name {
+	case core.Bit, core.TinyInt, core.SmallInt, core.MediumInt, core.Int, core.Integer, core.Serial,
+		core.BigInt, core.BigSerial:
+		return "int"
+	case core.Date:
+		return "date"
+	case core.Time:
+		return "time"
+	case core.DateTime, core.TimeStamp, core.TimeStampz:
+		return "datetime"
+	case core.Decimal, core.Numeric:
+		return "Decimal"
+	case core.Real, core.Float, core.Double:
+		return "float"
+	case core.Binary, core.VarBinary, core.TinyBlob, core.Blob, core.MediumBlob, core.LongBlob, core.Bytea:
+		return "bytes"
+	case core.Bool:
+		return "bool"
+	default:
+		return "str"
+	}
+}
+
+func genPythonImports(tables []*core.Table) map[string]string {
+	imports := make(map[string]string)
+
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			if imp, ok := pythonImports[pythonType(col.SQLType)]; ok {
+				imports[imp] = imp
+			}
+			if col.Nullable && !col.IsPrimaryKey {
+				imports["from typing import Optional"] = "from typing import Optional"
+			}
+		}
+	}
+	return imports
+}
@@ -12,6 +12,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -32,7 +33,158 @@ var CmdReverse = &Command{
 	Long: `
 according database's tables and columns to generate codes for Go, C++ and etc.
 
-    -s                Generated one go file for every table
+    -s                Generated all tables in one file
+    -multifile        Generated one file for every table even when -s is given, one file for
+                      every table is the default anyway
+    -sort-fields      Sort the fields by column name instead of the order of the table, the
+                      columns of a composite primary key stay first in the order of the key
+    -pk-first         Put the primary key fields first
+    -ddl              Read the tables from the CREATE TABLE statements of the DDL file given as
+                      datasourceName instead of the database, only mysql is supported
+    -schemas=list     Comma separated schemas whose tables are generated instead of the schema
+                      of the config, e.g. public,auth. TableName methods return the schema
+                      qualified names and tables of the same name are prefixed by their schema
+    -schema-dirs      Generate the tables of every schema of -schemas in a subdirectory and
+                      package named after the schema
+    -changed-cache=file
+                      JSON file recording a signature of the columns and indexes of every
+                      table, only the tables changed since the last run are generated. A
+                      change of the options or templates regenerates all of them
+    -no-format        Write the output of the templates without formatting it, for debugging
+                      templates
+    -parallel=n       Generate, format and write the files of -multifile by n workers,
+                      default is 1. The files of the other tables are written when one fails
+    -dry-run          Print the generated files to stdout, each after a line naming it, instead
+                      of writing them
+    -sql-null         Use sql.NullString, sql.NullInt64 and etc. for nullable columns
+    -sql-null-generic Use sql.Null[T] of Go 1.22 for nullable columns, refused when the go.mod
+                      of the generated code asks for an older Go
+    -nullable-ptr-smart
+                      Use pointer types for nullable columns without a default value,
+                      can not be used with -sql-null
+    -json-raw         Use json.RawMessage for JSON columns
+    -tinyint1-bool    Use bool for TINYINT(1) columns
+    -enum-types       Generate a named string type with constants for every ENUM column,
+                      a type named like a struct gets the suffix Enum
+    -enum-scanner     Generate Valid, Scan and Value methods for the types of -enum-types,
+                      refusing the values which aren't options
+    -enums-file       Put the types of -enum-types of all the tables in enums.go instead of
+                      the files of their tables
+    -set-slice        Use a slice type for SET columns, of strings or of the named type with
+                      -enum-types, stored as the options joined by commas
+    -json-omitempty   Add omitempty to the json tags of all but primary key columns
+    -json-omitempty-nullable
+                      Add omitempty to the json tags of nullable columns only
+    -db-tag           Add db tags for sqlx
+    -yaml-tag         Add yaml tags
+    -toml-tag         Add toml tags
+    -validate         Add validate tags of go-playground/validator, required for NOT NULL
+                      columns without a default and max for the length of text columns
+    -gin-binding      Add binding:"required" tags for Gin to NOT NULL columns without a default
+    -col-consts       Generate a constant of every column name, e.g. UserColName = "name"
+    -repository       Generate a Repository interface with Get, Find, Insert, Update and Delete
+                      methods for every table, the ones by primary key need one
+    -slice-types      Generate a slice type of every struct, e.g. Users for User, with a method
+                      returning the values of a single column primary key, e.g. IDs
+    -field-facts      Put a comment after every field summarizing its column, like
+                      // nullable, default 0, indexed
+    -explicit-colname Put the quoted column name first in the xorm tags, for mappers which
+                      don't map the field names back to the column names
+    -compact-tags     Separate the tokens of the xorm tags by single spaces instead of padding
+                      them into columns
+    -skip-empty-tokens
+                      Leave the placeholders of absent attributes out of the xorm tags, but
+                      keep the padding of the others
+    -gorm             Generate gorm tags instead of xorm tags
+    -timestamp-tags   Tag time columns defaulting to CURRENT_TIMESTAMP as created, or as
+                      updated with ON UPDATE CURRENT_TIMESTAMP, instead of the default
+    -infer-timestamps Tag time columns named by -created-names as created and the ones named
+                      by -updated-names as updated
+    -created-names=globs
+                      Comma separated column name patterns of -infer-timestamps, default is
+                      created_at,create_time
+    -updated-names=globs
+                      Comma separated column name patterns of -infer-timestamps, default is
+                      updated_at,update_time
+    -doc-comment      Put the table and column comments as doc comments above the structs
+                      and their fields
+    -accessors        Generate Get and Set methods for every field, the getters of pointer
+                      fields return the zero value when the field is nil
+    -base-model       Generate a BaseModel struct of the columns all tables have in common,
+                      embedded by the tables instead of these columns, go only
+    -base-columns=names
+                      Comma separated column names of BaseModel instead of the common ones,
+                      embedded by the tables having all of them
+    -columns-method   Generate a Columns method returning the column names of the struct
+    -pk-method        Generate a PrimaryKeys method returning the primary key column names
+    -insert-struct    Generate a struct named with an Insert suffix for every table, without the
+                      auto increment, created and updated columns
+    -or-empty         Generate a method named with an OrEmpty suffix for every pointer field,
+                      returning its value or the zero value when it's nil
+    -tablename-method Generate a TableName method returning the table name in the database
+    -no-index-tags    Leave the indexes out of the tags
+    -unique-index-only
+                      Put only the unique indexes in the tags
+    -keep-index-names Put the names of single column indexes in the tags too, like the ones
+                      of composite indexes
+    -soft-delete      Tag nullable time columns named by -deleted-names as deleted
+    -deleted-names=globs
+                      Comma separated column name patterns of -soft-delete, default is
+                      deleted_at,deleted
+    -goimports        Format the generated Go codes with goimports to fix their imports
+    -uuid             Use uuid.UUID from github.com/google/uuid for UUID columns
+    -big-int          Use *big.Int for DECIMAL and NUMERIC columns of scale 0 and precision
+                      over 18, which don't fit in int64
+    -type-map=file    JSON or TOML file mapping column names or SQL types to Go types
+    -decimal=lib      Go type for DECIMAL and NUMERIC columns, only shopspring is supported
+    -geometry=type    Go type for spatial columns like GEOMETRY and POINT, bytes for their WKB,
+                      string or a type qualified by its import path, e.g.
+                      github.com/twpayne/go-geom/encoding/ewkb.Point
+    -time-type=type   Go type for time columns instead of time.Time, qualified by its import
+                      path, e.g. github.com/acme/mytime.Time
+    -date-type=type   Go type for DATE columns, time, string or a type qualified by its import
+                      path like cloud.google.com/go/civil.Date, default is the time type
+    -time-of-day=type Go type for TIME columns, duration for time.Duration, string or a
+                      qualified type, default is the time type
+    -unknown-type=type
+                      Go type for the columns of unknown SQL types, default is string,
+                      e.g. interface{}. A warning names each of them
+    -json-case=case   Naming of json tags, camel or snake, default is the column name.
+                      camel doesn't upper case the acronyms, user_id is userId
+    -yaml-case=case   Naming of yaml tags like -json-case
+    -toml-case=case   Naming of toml tags like -json-case
+    -filename-case=case
+                      Naming of the files of -multifile, snake, default is the table name.
+                      Names clashing after the conversion get a numeric suffix
+    -header=file      File whose content is put at the top of every generated file
+    -build-tags=expr  Build constraint put above the header of every generated Go file as
+                      //go:build and // +build lines, e.g. "mysql && !sqlite"
+    -tag-order=names  Comma separated order of the struct tags, e.g. json,db,xorm
+    -include=globs    Comma separated table name patterns, only matched tables are generated
+    -exclude=globs    Comma separated table name patterns, matched tables are not generated,
+                      even when they match -include
+    -ignore-columns=globs
+                      Comma separated table.column or column patterns of the columns not
+                      generated. They are removed from the indexes too, an ignored primary
+                      key leaves the struct without pk
+    -read-only=globs  Comma separated table.column or column patterns of the columns tagged
+                      with -> so xorm only reads them, or <-:false with -gorm
+    -write-only=globs Comma separated table.column or column patterns of the columns tagged
+                      with <- so xorm only writes them, or ->:false with -gorm
+    -mapper=name      Mapper of the table and column names, snake, same or gonic, default is
+                      snake
+    -acronyms=list    Comma separated acronyms upper cased in the Go names, e.g. UserID instead
+                      of UserId. default stands for the built-in list, e.g. default,GRPC
+    -templates=dir    Dir of .tmpl files replacing the templates of tmplPath with the same
+                      name, e.g. struct.go.tmpl replaces struct.go.tpl
+    -strip-prefix=list
+                      Comma separated prefixes stripped from the table names before they are
+                      mapped, e.g. t_,tbl_. TableName methods keep the real table names
+    -strip-suffix=list
+                      Comma separated suffixes stripped from the table names like -strip-prefix
+    -struct-prefix=s  Prefix of the generated struct names, e.g. DB
+    -struct-suffix=s  Suffix of the generated struct names, e.g. Entity
+    -package=name     Package name of the generated codes, default is the generatedPath's base name
     driverName        Database driver name, now supported four: mysql mymysql sqlite3 postgres
     datasourceName    Database connection uri, for detail infomation please visit driver's project page
     tmplPath          Template dir for generated. the default templates dir has provide 1 template
@@ -45,17 +197,171 @@ according database's tables and columns to generate codes for Go, C++ and etc.
 func init() {
 	CmdReverse.Run = runReverse
 	CmdReverse.Flags = map[string]bool{
-		"-s": false,
-		"-l": false,
+		"-s":                       false,
+		"-multifile":               false,
+		"-l":                       false,
+		"-sql-null":                false,
+		"-sql-null-generic":        false,
+		"-uuid":                    false,
+		"-nullable-ptr-smart":      false,
+		"-json-raw":                false,
+		"-tinyint1-bool":           false,
+		"-enum-types":              false,
+		"-json-omitempty":          false,
+		"-json-omitempty-nullable": false,
+		"-db-tag":                  false,
+		"-gorm":                    false,
+		"-timestamp-tags":          false,
+		"-soft-delete":             false,
+		"-infer-timestamps":        false,
+		"-tablename-method":        false,
+		"-accessors":               false,
+		"-base-model":              false,
+		"-columns-method":          false,
+		"-pk-method":               false,
+		"-insert-struct":           false,
+		"-dry-run":                 false,
+		"-no-format":               false,
+		"-field-facts":             false,
+		"-repository":              false,
+		"-compact-tags":            false,
+		"-col-consts":              false,
+		"-slice-types":             false,
+		"-skip-empty-tokens":       false,
+		"-ddl":                     false,
+		"-schema-dirs":             false,
+		"-sort-fields":             false,
+		"-pk-first":                false,
+		"-or-empty":                false,
+		"-explicit-colname":        false,
+		"-enums-file":              false,
+		"-enum-scanner":            false,
+		"-yaml-tag":                false,
+		"-toml-tag":                false,
+		"-set-slice":               false,
+		"-big-int":                 false,
+		"-no-index-tags":           false,
+		"-unique-index-only":       false,
+		"-keep-index-names":        false,
+		"-validate":                false,
+		"-gin-binding":             false,
+		"-doc-comment":             false,
+		"-goimports":               false,
+	}
+	CmdReverse.Options = map[string]string{
+		"-type-map":       "",
+		"-decimal":        "",
+		"-json-case":      "",
+		"-yaml-case":      "",
+		"-toml-case":      "",
+		"-schemas":        "",
+		"-filename-case":  "",
+		"-changed-cache":  "",
+		"-parallel":       "",
+		"-build-tags":     "",
+		"-package":        "",
+		"-header":         "",
+		"-tag-order":      "",
+		"-include":        "",
+		"-exclude":        "",
+		"-ignore-columns": "",
+		"-read-only":      "",
+		"-write-only":     "",
+		"-acronyms":       "",
+		"-deleted-names":  "",
+		"-templates":      "",
+		"-base-columns":   "",
+		"-mapper":         "",
+		"-strip-prefix":   "",
+		"-strip-suffix":   "",
+		"-struct-prefix":  "",
+		"-struct-suffix":  "",
+		"-geometry":       "",
+		"-unknown-type":   "",
+		"-time-type":      "",
+		"-date-type":      "",
+		"-time-of-day":    "",
+		"-created-names":  "",
+		"-updated-names":  "",
 	}
 }
 
 var (
-	genJson    bool = false
-	genComment bool = false
-	schema     string
+	genJson               bool = false
+	genComment            bool = false
+	genSqlNull            bool = false
+	genSqlNullGeneric     bool
+	genUuid               bool = false
+	genNullablePtr        bool = false
+	genJsonRaw            bool = false
+	genTinyIntBool        bool = false
+	genEnumTypes          bool = false
+	genDbTag              bool = false
+	genGorm               bool = false
+	genTimestampTags      bool = false
+	genSoftDelete         bool = false
+	genInferTimestamps    bool = false
+	genDocComment         bool = false
+	genSetSlice           bool = false
+	genBigInt             bool = false
+	noIndexTags           bool = false
+	uniqueIndexOnly       bool = false
+	keepIndexNames        bool = false
+	genValidate           bool = false
+	genGinBinding         bool = false
+	genInsertStruct       bool = false
+	genAccessors          bool = false
+	genOrEmpty            bool = false
+	genRepository         bool = false
+	genSliceTypes         bool = false
+	useGoimports          bool = false
+	jsonOmitEmpty         bool = false
+	jsonOmitEmptyNullable bool = false
+	decimalLib            string
+	jsonCase              string
+	yamlCase              string
+	tomlCase              string
+	fileNameCase          string
+	changedTables         map[*core.Table]bool
+	parallel              int
+	genYamlTag            bool
+	genTomlTag            bool
+	tagOrder              []string
+	geometryType          string
+	unknownType           string = "string"
+	timeType              string
+	dateType              string
+	explicitColName       bool
+	noFormat              bool
+	genFieldFacts         bool
+	compactTags           bool
+	skipEmptyTokens       bool
+	genEnumsFile          bool
+	genEnumScanner        bool
+	timeOfDayType         string
+	dryRun                bool
+	filesWritten          int
+	filesSkipped          int
+	structPrefix          string
+	structSuffix          string
+	deletedNames          []string
+	createdNames          []string
+	updatedNames          []string
+	upperAcronymNames     bool = false
+	columnDirections      map[*core.Column]string
+	realTableNames        map[*core.Table]string
+	schema                string
+	schemaDirs            bool
+	tableSchemas          map[*core.Table]string
 )
 
+// generatedMarker marks the generated Go files, so tools like golint skip
+// them.
+const generatedMarker = "// Code generated by xorm reverse. DO NOT EDIT."
+
+// enumsFileName is the name of the file of -enums-file.
+const enumsFileName = "enums"
+
 func printReversePrompt(flag string) {
 }
 
@@ -63,6 +369,42 @@ type Tmpl struct {
 	Tables  []*core.Table
 	Imports map[string]string
 	Models  string
+	// Package is the package name given by -package, or Models if blank.
+	Package string
+	// TableNameMethod is set by -tablename-method to generate a TableName
+	// method returning the table name in the database.
+	TableNameMethod bool
+	// Accessors is set by -accessors to generate getters and setters for
+	// the fields.
+	Accessors bool
+	// ColumnsMethod is set by -columns-method to generate a Columns method
+	// returning the column names in the order of the fields.
+	ColumnsMethod bool
+	// PrimaryKeysMethod is set by -pk-method to generate a PrimaryKeys
+	// method returning the primary key column names.
+	PrimaryKeysMethod bool
+	// InsertStruct is set by -insert-struct to generate a struct for
+	// inserts of every table, without the columns set by the database or
+	// xorm.
+	InsertStruct bool
+	// OrEmpty is set by -or-empty to generate methods returning the value
+	// of the pointer fields or the zero value when they are nil.
+	OrEmpty bool
+	// Repository is set by -repository to generate an interface with the
+	// CRUD methods of every table.
+	Repository bool
+	// SliceTypes is set by -slice-types to generate a slice type of every
+	// struct, with a method collecting the values of a single primary key.
+	SliceTypes bool
+	// ColConsts is set by -col-consts to generate a constant of every
+	// column name, named by the struct and the field.
+	ColConsts bool
+	// Enums are the named types of all the tables for the enums file of
+	// -enums-file.
+	Enums []*GoEnum
+	// BaseModel holds the columns of the BaseModel struct to generate, it's
+	// nil when the file has no BaseModel.
+	BaseModel *core.Table
 }
 
 func dirExists(dir string) bool {
@@ -78,7 +420,7 @@ func dirExists(dir string) bool {
 }
 
 func runReverse(cmd *Command, args []string) {
-	num := checkFlags(cmd.Flags, args, printReversePrompt)
+	num := checkFlags(cmd.Flags, cmd.Options, args, printReversePrompt)
 	if num == -1 {
 		return
 	}
@@ -93,6 +435,181 @@ func runReverse(cmd *Command, args []string) {
 	if use, ok := cmd.Flags["-s"]; ok {
 		isMultiFile = !use
 	}
+	if cmd.Flags["-multifile"] {
+		isMultiFile = true
+	}
+	genSqlNull = cmd.Flags["-sql-null"]
+	genUuid = cmd.Flags["-uuid"]
+	genNullablePtr = cmd.Flags["-nullable-ptr-smart"]
+	genJsonRaw = cmd.Flags["-json-raw"]
+	genTinyIntBool = cmd.Flags["-tinyint1-bool"]
+	genEnumTypes = cmd.Flags["-enum-types"]
+	genEnumsFile = cmd.Flags["-enums-file"]
+	genEnumScanner = cmd.Flags["-enum-scanner"]
+	genYamlTag = cmd.Flags["-yaml-tag"]
+	genTomlTag = cmd.Flags["-toml-tag"]
+	genDbTag = cmd.Flags["-db-tag"]
+	genGorm = cmd.Flags["-gorm"]
+	genTimestampTags = cmd.Flags["-timestamp-tags"]
+	genSoftDelete = cmd.Flags["-soft-delete"]
+	genInferTimestamps = cmd.Flags["-infer-timestamps"]
+	genDocComment = cmd.Flags["-doc-comment"]
+	genSetSlice = cmd.Flags["-set-slice"]
+	genBigInt = cmd.Flags["-big-int"]
+	noIndexTags = cmd.Flags["-no-index-tags"]
+	uniqueIndexOnly = cmd.Flags["-unique-index-only"]
+	keepIndexNames = cmd.Flags["-keep-index-names"]
+	genValidate = cmd.Flags["-validate"]
+	genGinBinding = cmd.Flags["-gin-binding"]
+	genInsertStruct = cmd.Flags["-insert-struct"]
+	genAccessors = cmd.Flags["-accessors"]
+	genOrEmpty = cmd.Flags["-or-empty"]
+	genRepository = cmd.Flags["-repository"]
+	genSliceTypes = cmd.Flags["-slice-types"]
+	explicitColName = cmd.Flags["-explicit-colname"]
+	noFormat = cmd.Flags["-no-format"]
+	genFieldFacts = cmd.Flags["-field-facts"]
+	compactTags = cmd.Flags["-compact-tags"]
+	skipEmptyTokens = cmd.Flags["-skip-empty-tokens"]
+	useGoimports = cmd.Flags["-goimports"]
+	jsonOmitEmpty = cmd.Flags["-json-omitempty"]
+	jsonOmitEmptyNullable = cmd.Flags["-json-omitempty-nullable"]
+	genSqlNullGeneric = cmd.Flags["-sql-null-generic"]
+	if genSqlNull && genNullablePtr {
+		fmt.Println("-sql-null and -nullable-ptr-smart can not be used together")
+		return
+	}
+	if genSqlNullGeneric && (genSqlNull || genNullablePtr) {
+		fmt.Println("-sql-null-generic can not be used with -sql-null or -nullable-ptr-smart")
+		return
+	}
+
+	jsonCase = cmd.Options["-json-case"]
+	yamlCase = cmd.Options["-yaml-case"]
+	tomlCase = cmd.Options["-toml-case"]
+	for _, c := range []string{jsonCase, yamlCase, tomlCase} {
+		if c != "" && c != "camel" && c != "snake" {
+			fmt.Println("Unsupported tag case", c)
+			return
+		}
+	}
+	parallel = 1
+	if p := cmd.Options["-parallel"]; p != "" {
+		var err error
+		if parallel, err = strconv.Atoi(p); err != nil || parallel < 1 {
+			fmt.Println("Invalid -parallel", p)
+			return
+		}
+	}
+
+	fileNameCase = cmd.Options["-filename-case"]
+	if fileNameCase != "" && fileNameCase != "snake" {
+		fmt.Println("Unsupported file name case", fileNameCase)
+		return
+	}
+
+	tagOrder = nil
+	if o := cmd.Options["-tag-order"]; o != "" {
+		for _, name := range strings.Split(o, ",") {
+			name = strings.TrimSpace(name)
+			if !isTagName(name) {
+				fmt.Println("Unsupported tag", name)
+				return
+			}
+			tagOrder = append(tagOrder, name)
+		}
+	}
+
+	patterns := append(splitPatterns(cmd.Options["-include"]), splitPatterns(cmd.Options["-exclude"])...)
+	patterns = append(patterns, splitPatterns(cmd.Options["-ignore-columns"])...)
+	patterns = append(patterns, splitPatterns(cmd.Options["-read-only"])...)
+	patterns = append(patterns, splitPatterns(cmd.Options["-deleted-names"])...)
+	patterns = append(patterns, splitPatterns(cmd.Options["-created-names"])...)
+	patterns = append(patterns, splitPatterns(cmd.Options["-updated-names"])...)
+	for _, p := range append(patterns, splitPatterns(cmd.Options["-write-only"])...) {
+		if _, err := path.Match(p, ""); err != nil {
+			fmt.Println("Invalid pattern", p)
+			return
+		}
+	}
+
+	deletedNames = splitPatterns(cmd.Options["-deleted-names"])
+	if len(deletedNames) == 0 {
+		deletedNames = []string{"deleted_at", "deleted"}
+	}
+	createdNames = splitPatterns(cmd.Options["-created-names"])
+	if len(createdNames) == 0 {
+		createdNames = []string{"created_at", "create_time"}
+	}
+	updatedNames = splitPatterns(cmd.Options["-updated-names"])
+	if len(updatedNames) == 0 {
+		updatedNames = []string{"updated_at", "update_time"}
+	}
+
+	upperAcronymNames = cmd.Options["-acronyms"] != ""
+	if upperAcronymNames {
+		loadAcronyms(cmd.Options["-acronyms"])
+	}
+
+	overrideDir := cmd.Options["-templates"]
+	if overrideDir != "" && !dirExists(overrideDir) {
+		fmt.Println("Template override dir", overrideDir, "does not exist")
+		return
+	}
+
+	structPrefix = cmd.Options["-struct-prefix"]
+	structSuffix = cmd.Options["-struct-suffix"]
+
+	mapper = mappers["snake"]
+	if name := cmd.Options["-mapper"]; name != "" {
+		m, ok := mappers[name]
+		if !ok {
+			fmt.Println("Unsupported mapper", name)
+			return
+		}
+		mapper = m
+	}
+
+	customImportPaths = make(map[string]string)
+	if t, ok := optionGoType(cmd.Options["-geometry"], geometryGoTypes); ok {
+		geometryType = t
+	} else {
+		fmt.Println("Unsupported geometry type", cmd.Options["-geometry"])
+		return
+	}
+	if t, ok := optionGoType(cmd.Options["-date-type"], temporalGoTypes); ok {
+		dateType = t
+	} else {
+		fmt.Println("Unsupported date type", cmd.Options["-date-type"])
+		return
+	}
+	if t, ok := optionGoType(cmd.Options["-time-of-day"], temporalGoTypes); ok {
+		timeOfDayType = t
+	} else {
+		fmt.Println("Unsupported time of day type", cmd.Options["-time-of-day"])
+		return
+	}
+
+	for _, name := range []string{"-time-type", "-unknown-type"} {
+		if _, _, err := splitQualifiedType(cmd.Options[name]); err != nil {
+			fmt.Println(name, err)
+			return
+		}
+	}
+	if t := cmd.Options["-time-type"]; t != "" {
+		timeType, _ = qualifiedGoType(t)
+	}
+
+	unknownType = "string"
+	if t := cmd.Options["-unknown-type"]; t != "" {
+		unknownType, _ = qualifiedGoType(t)
+	}
+
+	decimalLib = cmd.Options["-decimal"]
+	if decimalLib != "" && decimalLib != "shopspring" {
+		fmt.Println("Unsupported decimal type", decimalLib)
+		return
+	}
 
 	curPath, err := os.Getwd()
 	if err != nil {
@@ -100,6 +617,14 @@ func runReverse(cmd *Command, args []string) {
 		return
 	}
 
+	if f := cmd.Options["-type-map"]; f != "" {
+		typeMap, err = loadTypeMap(f)
+		if err != nil {
+			log.Errorf("%v", err)
+			return
+		}
+	}
+
 	var genDir string
 	var model string
 	var filterPat *regexp.Regexp
@@ -126,6 +651,18 @@ func runReverse(cmd *Command, args []string) {
 		genDir = path.Join(curPath, model)
 	}
 
+	pkgName := model
+	if p := cmd.Options["-package"]; p != "" {
+		pkgName = p
+	}
+
+	if genSqlNullGeneric {
+		if err := checkSqlNullGeneric(genDir); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+
 	dir, err := filepath.Abs(args[2])
 	if err != nil {
 		log.Errorf("%v", err)
@@ -168,25 +705,56 @@ func runReverse(cmd *Command, args []string) {
 		return
 	}
 
-	os.MkdirAll(genDir, os.ModePerm)
+	var header string
+	if f := cmd.Options["-header"]; f != "" {
+		bs, err := ioutil.ReadFile(f)
+		if err != nil {
+			log.Errorf("%v", err)
+			return
+		}
+		header = strings.TrimRight(string(bs), "\r\n") + "\n\n"
+	}
+	if lang == "go" {
+		header += generatedMarker + "\n\n"
+	}
+	if tags := cmd.Options["-build-tags"]; tags != "" {
+		if lang != "go" {
+			fmt.Println("-build-tags is only supported for go")
+			return
+		}
+		lines, err := buildConstraint(tags)
+		if err != nil {
+			log.Errorf("-build-tags: %v", err)
+			return
+		}
+		header = lines + header
+	}
+
+	dryRun = cmd.Flags["-dry-run"]
+	if !dryRun {
+		os.MkdirAll(genDir, os.ModePerm)
+	}
 
 	supportComment = (args[0] == "mysql" || args[0] == "mymysql")
 
-	Orm, err := xorm.NewEngine(args[0], args[1])
-	if err != nil {
-		log.Errorf("%v", err)
+	var tables []*core.Table
+	tableSchemas = make(map[*core.Table]string)
+	schemaDirs = cmd.Flags["-schema-dirs"]
+	if schemas := splitPatterns(cmd.Options["-schemas"]); len(schemas) > 0 {
+		tables, err = schemasTables(args[0], args[1], schemas)
+	} else if schemaDirs {
+		log.Errorf("-schema-dirs needs -schemas")
 		return
+	} else if cmd.Flags["-ddl"] {
+		tables, err = ddlTables(args[0], args[1])
+	} else {
+		tables, err = dbTables(args[0], args[1], schema)
 	}
-
-	if len(schema) > 0 {
-		Orm.SetSchema(schema)
-	}
-
-	tables, err := Orm.DBMetas()
 	if err != nil {
 		log.Errorf("%v", err)
 		return
 	}
+	normalizeUnsigned(tables)
 	if filterPat != nil && len(tables) > 0 {
 		size := 0
 		for _, t := range tables {
@@ -197,8 +765,153 @@ func runReverse(cmd *Command, args []string) {
 		}
 		tables = tables[:size]
 	}
+	tables = filterTables(tables, splitPatterns(cmd.Options["-include"]), splitPatterns(cmd.Options["-exclude"]))
+	if ignores := splitPatterns(cmd.Options["-ignore-columns"]); len(ignores) > 0 {
+		for i, table := range tables {
+			tables[i] = ignoreColumns(table, ignores)
+		}
+	}
+	if sortFields, pkFirst := cmd.Flags["-sort-fields"], cmd.Flags["-pk-first"]; sortFields || pkFirst {
+		for i, table := range tables {
+			tables[i] = sortColumns(table, sortFields, pkFirst)
+		}
+	}
 
-	filepath.Walk(dir, func(f string, info os.FileInfo, err error) error {
+	// directions are resolved before the table prefix is stripped, like
+	// the other patterns
+	columnDirections = make(map[*core.Column]string)
+	realTableNames = make(map[*core.Table]string)
+	stripPrefixes := splitPatterns(cmd.Options["-strip-prefix"])
+	stripSuffixes := splitPatterns(cmd.Options["-strip-suffix"])
+	readOnly := splitPatterns(cmd.Options["-read-only"])
+	writeOnly := splitPatterns(cmd.Options["-write-only"])
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			if matchColumn(readOnly, table, col) {
+				columnDirections[col] = "->"
+			} else if matchColumn(writeOnly, table, col) {
+				columnDirections[col] = "<-"
+			}
+		}
+	}
+
+	for _, table := range tables {
+		realTableNames[table] = table.Name
+		//[SWH|+]
+		if prefix != "" {
+			table.Name = strings.TrimPrefix(table.Name, prefix)
+		}
+		table.Name = stripAffixes(table.Name, stripPrefixes, strings.HasPrefix, strings.TrimPrefix)
+		table.Name = stripAffixes(table.Name, stripSuffixes, strings.HasSuffix, strings.TrimSuffix)
+		for _, col := range table.Columns() {
+			col.TableName = table.Name
+		}
+		for _, index := range checkIndexOrder(table) {
+			log.Warnf("index %v of table %v has columns %v which are not in the order of the table's columns",
+				index.Name, table.Name, index.Cols)
+		}
+	}
+	if !schemaDirs {
+		renameCollisions(tables)
+	}
+
+	var baseColumns []string
+	if lang == "go" && (cmd.Flags["-base-model"] || cmd.Options["-base-columns"] != "") {
+		baseColumns = splitPatterns(cmd.Options["-base-columns"])
+		if len(baseColumns) == 0 {
+			baseColumns = commonColumns(tables)
+		}
+	}
+	newBaseModel(tables, baseColumns)
+	if lang == "go" {
+		tbs := tables
+		if baseModel != nil {
+			tbs = append([]*core.Table{baseModel}, tables...)
+		}
+		nameEnums(tbs)
+		aliasImports(genGoImports(tbs))
+	}
+
+	// with -changed-cache only the tables whose signature differs from the
+	// last run are generated, the cache is updated when all went well
+	changedTables = nil
+	var cache *TableCache
+	cacheFile := cmd.Options["-changed-cache"]
+	if cacheFile != "" {
+		old, err := readTableCache(cacheFile)
+		if err != nil {
+			log.Errorf("%v", err)
+			return
+		}
+		options := optionsSignature(os.Args[1:], []string{dir, overrideDir},
+			[]string{cmd.Options["-type-map"], cmd.Options["-header"]})
+		names := tableFileNames(tables, fileNameCase, reservedFileNames(lang))
+		cache = newTableCache(options, tables, names)
+		changedTables = cache.changed(old, tables)
+	}
+
+	// genTables generates the files of a template for the tables in
+	// genDir, which is the package of a schema with -schema-dirs
+	genTables := func(tmpl *template.Template, newFileName string, tables []*core.Table, genDir, model, pkgName string) error {
+		if !anyChanged(tables) {
+			return nil
+		}
+		ext := path.Ext(newFileName)
+
+		if genEnumsFile && lang == "go" {
+			if es := allEnums(tables); len(es) > 0 {
+				t := &Tmpl{Imports: enumImports(), Models: model, Package: pkgName, Enums: es}
+				if err := genFile(tmpl, langTmpl.Formater, header, path.Join(genDir, enumsFileName+ext), t); err != nil {
+					return err
+				}
+			}
+		}
+
+		if !isMultiFile {
+			tbs := tables
+			if baseModel != nil {
+				tbs = append([]*core.Table{baseModel}, tables...)
+			}
+			t := &Tmpl{Tables: tables, Imports: langTmpl.GenImports(tbs), Models: model, Package: pkgName,
+				TableNameMethod: cmd.Flags["-tablename-method"], Accessors: genAccessors,
+				ColumnsMethod:     cmd.Flags["-columns-method"],
+				PrimaryKeysMethod: cmd.Flags["-pk-method"],
+				InsertStruct:      genInsertStruct, OrEmpty: genOrEmpty,
+				Repository: genRepository, ColConsts: cmd.Flags["-col-consts"],
+				SliceTypes: genSliceTypes, BaseModel: baseModel}
+			return genFile(tmpl, langTmpl.Formater, header, path.Join(genDir, newFileName), t)
+		}
+
+		if baseModel != nil {
+			tbs := []*core.Table{baseModel}
+			t := &Tmpl{Imports: langTmpl.GenImports(tbs), Models: model, Package: pkgName, BaseModel: baseModel}
+			if err := genFile(tmpl, langTmpl.Formater, header, path.Join(genDir, baseModelName+ext), t); err != nil {
+				return err
+			}
+		}
+
+		names := fileNames(tables, fileNameCase, reservedFileNames(lang))
+		var jobs []genJob
+		for _, table := range tables {
+			if !isChanged(table) {
+				continue
+			}
+			// imports
+			tbs := []*core.Table{table}
+			t := &Tmpl{Tables: tbs, Imports: langTmpl.GenImports(tbs), Models: model, Package: pkgName,
+				TableNameMethod: cmd.Flags["-tablename-method"], Accessors: genAccessors,
+				ColumnsMethod:     cmd.Flags["-columns-method"],
+				PrimaryKeysMethod: cmd.Flags["-pk-method"],
+				InsertStruct:      genInsertStruct, OrEmpty: genOrEmpty,
+				Repository: genRepository, ColConsts: cmd.Flags["-col-consts"],
+				SliceTypes: genSliceTypes}
+			jobs = append(jobs, genJob{path.Join(genDir, names[table]+ext), t})
+		}
+
+		return genFiles(tmpl, langTmpl.Formater, header, jobs, parallel)
+	}
+
+	err = filepath.Walk(dir, func(f string, info os.FileInfo, err error) error {
 		if info.IsDir() {
 			return nil
 		}
@@ -213,6 +926,17 @@ func runReverse(cmd *Command, args []string) {
 			return err
 		}
 
+		// a .tmpl file of -templates replaces the built-in template
+		if overrideDir != "" {
+			o := filepath.Join(overrideDir, strings.TrimSuffix(info.Name(), ".tpl")+".tmpl")
+			if obs, err := ioutil.ReadFile(o); err == nil {
+				bs = obs
+			} else if !os.IsNotExist(err) {
+				log.Errorf("%v", err)
+				return err
+			}
+		}
+
 		t := template.New(f)
 		t.Funcs(langTmpl.Funcs)
 
@@ -222,104 +946,423 @@ func runReverse(cmd *Command, args []string) {
 			return err
 		}
 
-		var w *os.File
 		fileName := info.Name()
 		newFileName := fileName[:len(fileName)-4]
-		ext := path.Ext(newFileName)
-
-		if !isMultiFile {
-			w, err = os.Create(path.Join(genDir, newFileName))
-			if err != nil {
-				log.Errorf("%v", err)
+		if !schemaDirs {
+			return genTables(tmpl, newFileName, tables, genDir, model, pkgName)
+		}
+		for _, schema := range schemaNames(tables) {
+			dir := path.Join(genDir, schema)
+			if !dryRun {
+				os.MkdirAll(dir, os.ModePerm)
+			}
+			if err := genTables(tmpl, newFileName, tablesOfSchema(tables, schema), dir, schema, schema); err != nil {
 				return err
 			}
+		}
+		return nil
+	})
 
-			imports := langTmpl.GenImports(tables)
+	if !dryRun {
+		fmt.Printf("%d files written, %d unchanged\n", filesWritten, filesSkipped)
+	}
+	if cache != nil {
+		if len(changedTables) == 0 {
+			fmt.Println("No table changed")
+		} else {
+			fmt.Printf("Regenerated tables: %s\n", changedNames(changedTables))
+		}
+		if err == nil && !dryRun {
+			if err := writeTableCache(cacheFile, cache); err != nil {
+				log.Errorf("%v", err)
+			}
+		}
+	}
+}
 
-			tbls := make([]*core.Table, 0)
-			for _, table := range tables {
-				//[SWH|+]
-				if prefix != "" {
-					table.Name = strings.TrimPrefix(table.Name, prefix)
+// goModVersion returns the go version of the go.mod of the module the
+// directory is in. It's false when there is no go.mod or it has no go
+// directive.
+func goModVersion(dir string) (string, bool) {
+	for {
+		bs, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(bs), "\n") {
+				fields := strings.Fields(line)
+				if len(fields) == 2 && fields[0] == "go" {
+					return fields[1], true
 				}
-				tbls = append(tbls, table)
 			}
+			return "", false
+		}
 
-			newbytes := bytes.NewBufferString("")
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
 
-			t := &Tmpl{Tables: tbls, Imports: imports, Models: model}
-			err = tmpl.Execute(newbytes, t)
-			if err != nil {
-				log.Errorf("%v", err)
-				return err
-			}
+// checkSqlNullGeneric returns an error when the go.mod of the generated
+// code asks for a go version older than 1.22, which sql.Null[T] needs.
+// Without a go.mod the version isn't known and it's left to the user.
+func checkSqlNullGeneric(dir string) error {
+	if v, ok := goModVersion(dir); ok && !versionAtLeast(v, 1, 22) {
+		return fmt.Errorf("-sql-null-generic needs go 1.22 or later, but the go.mod of %v has go %v", dir, v)
+	}
+	return nil
+}
 
-			tplcontent, err := ioutil.ReadAll(newbytes)
-			if err != nil {
-				log.Errorf("%v", err)
-				return err
-			}
-			var source string
-			if langTmpl.Formater != nil {
-				source, err = langTmpl.Formater(string(tplcontent))
-				if err != nil {
-					log.Errorf("%v", err)
-					return err
-				}
-			} else {
-				source = string(tplcontent)
-			}
+// versionAtLeast reports whether a go version like 1.21 or 1.22.3 is at
+// least major.minor.
+func versionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(version, ".", 3)
+	maj, _ := strconv.Atoi(parts[0])
+	min := 0
+	if len(parts) > 1 {
+		min, _ = strconv.Atoi(parts[1])
+	}
+	return maj > major || maj == major && min >= minor
+}
 
-			w.WriteString(source)
-			w.Close()
-		} else {
-			for _, table := range tables {
-				//[SWH|+]
-				if prefix != "" {
-					table.Name = strings.TrimPrefix(table.Name, prefix)
-				}
-				// imports
-				tbs := []*core.Table{table}
-				imports := langTmpl.GenImports(tbs)
+// isChanged reports whether the table is generated, which are all of them
+// without -changed-cache.
+func isChanged(table *core.Table) bool {
+	return changedTables == nil || changedTables[table]
+}
 
-				w, err := os.Create(path.Join(genDir, table.Name+ext))
-				if err != nil {
-					log.Errorf("%v", err)
-					return err
-				}
-				defer w.Close()
+// anyChanged reports whether any of the tables is generated.
+func anyChanged(tables []*core.Table) bool {
+	for _, table := range tables {
+		if isChanged(table) {
+			return true
+		}
+	}
+	return false
+}
 
-				newbytes := bytes.NewBufferString("")
+// fileNames returns the names of the files of the tables without their
+// extension, in the case of -filename-case. A name which is taken by an
+// earlier table or reserved, ignoring the case for case insensitive file
+// systems, gets the first free suffix like _2.
+func fileNames(tables []*core.Table, nameCase string, reserved []string) map[*core.Table]string {
+	taken := make(map[string]bool)
+	for _, name := range reserved {
+		taken[strings.ToLower(name)] = true
+	}
+	names := make(map[*core.Table]string)
+	for _, table := range tables {
+		base := toCase(table.Name, nameCase)
+		name := base
+		for i := 2; taken[strings.ToLower(name)]; i++ {
+			name = base + "_" + strconv.Itoa(i)
+		}
+		taken[strings.ToLower(name)] = true
+		names[table] = name
+	}
+	return names
+}
 
-				t := &Tmpl{Tables: tbs, Imports: imports, Models: model}
-				err = tmpl.Execute(newbytes, t)
-				if err != nil {
-					log.Errorf("%v", err)
-					return err
-				}
+// reservedFileNames returns the names of the generated files which aren't
+// of a table.
+func reservedFileNames(lang string) []string {
+	reserved := []string{}
+	if baseModel != nil {
+		reserved = append(reserved, baseModelName)
+	}
+	if genEnumsFile && lang == "go" {
+		reserved = append(reserved, enumsFileName)
+	}
+	return reserved
+}
 
-				tplcontent, err := ioutil.ReadAll(newbytes)
-				if err != nil {
-					log.Errorf("%v", err)
-					return err
-				}
-				var source string
-				if langTmpl.Formater != nil {
-					source, err = langTmpl.Formater(string(tplcontent))
-					if err != nil {
-						log.Errorf("%v-%v", err, string(tplcontent))
-						return err
-					}
-				} else {
-					source = string(tplcontent)
-				}
+// tableFileNames returns the names of fileNames of all the tables, which
+// are given per schema with -schema-dirs as their files are.
+func tableFileNames(tables []*core.Table, nameCase string, reserved []string) map[*core.Table]string {
+	if !schemaDirs {
+		return fileNames(tables, nameCase, reserved)
+	}
+	names := make(map[*core.Table]string)
+	for _, schema := range schemaNames(tables) {
+		for table, name := range fileNames(tablesOfSchema(tables, schema), nameCase, reserved) {
+			names[table] = name
+		}
+	}
+	return names
+}
 
-				w.WriteString(source)
-				w.Close()
+// dbTables reads the tables of the database.
+func dbTables(driverName, dataSourceName, schema string) ([]*core.Table, error) {
+	Orm, err := xorm.NewEngine(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(schema) > 0 {
+		Orm.SetSchema(schema)
+	}
+	tables, err := Orm.DBMetas()
+	if err != nil {
+		return nil, err
+	}
+	if driverName == "mysql" || driverName == "mymysql" {
+		if err := markMysqlUnsigned(Orm, tables); err != nil {
+			return nil, err
+		}
+	}
+	return tables, nil
+}
+
+// markMysqlUnsigned records the unsigned columns of MySQL in
+// unsignedColumns, since the dialect of xorm drops the modifier from the
+// column types it reads.
+func markMysqlUnsigned(Orm *xorm.Engine, tables []*core.Table) error {
+	rows, err := Orm.Query("SELECT TABLE_NAME, COLUMN_NAME FROM INFORMATION_SCHEMA.COLUMNS" +
+		" WHERE TABLE_SCHEMA = DATABASE() AND COLUMN_TYPE LIKE '%unsigned%'")
+	if err != nil {
+		return err
+	}
+	byName := make(map[string]*core.Table, len(tables))
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+	for _, row := range rows {
+		if table, ok := byName[string(row["TABLE_NAME"])]; ok {
+			if col := table.GetColumn(string(row["COLUMN_NAME"])); col != nil {
+				unsignedColumns[col] = true
 			}
 		}
+	}
+	return nil
+}
 
+// genFile executes the template on data, prepends the header, formats the
+// result if formater is not nil and -no-format isn't given, and writes it
+// to fileName. With -dry-run it's printed to stdout after a line naming the
+// file instead. A file whose content wouldn't change isn't written.
+func genFile(tmpl *template.Template, formater func(string) (string, error), header, fileName string, data *Tmpl) error {
+	source, err := execTemplate(tmpl, header, data)
+	if err != nil {
+		return err
+	}
+	source, formatErr := formatSource(formater, fileName, source)
+	if err := writeSource(fileName, source); err != nil {
+		return err
+	}
+	return formatErr
+}
+
+// execTemplate executes the template on data and prepends the header.
+func execTemplate(tmpl *template.Template, header string, data *Tmpl) (string, error) {
+	newbytes := bytes.NewBufferString("")
+	err := tmpl.Execute(newbytes, data)
+	if err != nil {
+		log.Errorf("%v", err)
+		return "", err
+	}
+
+	tplcontent, err := ioutil.ReadAll(newbytes)
+	if err != nil {
+		log.Errorf("%v", err)
+		return "", err
+	}
+	return header + string(tplcontent), nil
+}
+
+// formatSource formats the source of the file unless formater is nil or
+// -no-format is given. The source which can't be formatted is returned as
+// it is with the error, so it's still written and the template producing
+// it can be fixed.
+func formatSource(formater func(string) (string, error), fileName, source string) (string, error) {
+	if formater == nil || noFormat {
+		return source, nil
+	}
+	formatted, err := formater(source)
+	if err != nil {
+		log.Errorf("format %v: %v", fileName, err)
+		return source, err
+	}
+	return formatted, nil
+}
+
+// writeSource writes the source to the file, or prints it to stdout after
+// a line naming the file with -dry-run.
+func writeSource(fileName, source string) error {
+	if dryRun {
+		fmt.Printf("==> %s <==\n%s\n", fileName, source)
+		return nil
+	}
+
+	// an unchanged file is left as it is to keep its mtime
+	if old, err := ioutil.ReadFile(fileName); err == nil && string(old) == source {
+		filesMu.Lock()
+		filesSkipped++
+		filesMu.Unlock()
 		return nil
+	}
+
+	w, err := os.Create(fileName)
+	if err != nil {
+		log.Errorf("%v", err)
+		return err
+	}
+	_, err = w.WriteString(source)
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Errorf("%v", err)
+		return err
+	}
+
+	filesMu.Lock()
+	filesWritten++
+	filesMu.Unlock()
+	return nil
+}
+
+func splitPatterns(s string) []string {
+	var res []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			res = append(res, p)
+		}
+	}
+	return res
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stripAffixes strips the first of the affixes the name has, unless that
+// leaves nothing of the name.
+func stripAffixes(name string, affixes []string, has func(string, string) bool,
+	trim func(string, string) string) string {
+	for _, a := range affixes {
+		if has(name, a) && len(name) > len(a) {
+			return trim(name, a)
+		}
+	}
+	return name
+}
+
+// matchColumn reports whether the column matches any of the patterns,
+// which are either table.column or column.
+func matchColumn(patterns []string, table *core.Table, col *core.Column) bool {
+	return matchAny(patterns, col.Name) || matchAny(patterns, table.Name+"."+col.Name)
+}
+
+// filterTables keeps the tables matching any include pattern, or all
+// tables when there is none, and drops the ones matching an exclude
+// pattern.
+func filterTables(tables []*core.Table, include, exclude []string) []*core.Table {
+	size := 0
+	for _, t := range tables {
+		if (len(include) == 0 || matchAny(include, t.Name)) && !matchAny(exclude, t.Name) {
+			tables[size] = t
+			size++
+		}
+	}
+	return tables[:size]
+}
+
+// copyTable returns a table like the given one with the columns, but
+// without indexes. The copy is in the schema of the table.
+func copyTable(table *core.Table, cols []*core.Column) *core.Table {
+	t := core.NewEmptyTable()
+	t.Name = table.Name
+	t.Type = table.Type
+	t.StoreEngine = table.StoreEngine
+	t.Charset = table.Charset
+	t.Comment = table.Comment
+	for _, col := range cols {
+		t.AddColumn(col)
+	}
+	if schema, ok := tableSchemas[table]; ok {
+		tableSchemas[t] = schema
+	}
+	return t
+}
+
+// sortColumns returns a copy of the table with the columns sorted by name
+// if byName is set, and the primary key columns first if pkFirst is set.
+// xorm orders a composite primary key by the fields, so its columns are
+// always put first in the order of the key.
+func sortColumns(table *core.Table, byName, pkFirst bool) *core.Table {
+	pkPos := make(map[string]int)
+	for i, name := range table.PrimaryKeys {
+		pkPos[name] = i
+	}
+	if len(table.PrimaryKeys) > 1 {
+		pkFirst = true
+	}
+
+	cols := append([]*core.Column(nil), table.Columns()...)
+	sort.SliceStable(cols, func(i, j int) bool {
+		if pkFirst && cols[i].IsPrimaryKey != cols[j].IsPrimaryKey {
+			return cols[i].IsPrimaryKey
+		}
+		if pkFirst && cols[i].IsPrimaryKey {
+			return pkPos[cols[i].Name] < pkPos[cols[j].Name]
+		}
+		return byName && cols[i].Name < cols[j].Name
 	})
 
+	t := copyTable(table, cols)
+	t.PrimaryKeys = table.PrimaryKeys
+	for name, index := range table.Indexes {
+		t.Indexes[name] = index
+	}
+	return t
+}
+
+// ignoreColumns returns a copy of the table without the columns matching
+// the patterns, which are either table.column or column. The columns are
+// removed from the table's indexes too, and indexes left without columns
+// are dropped.
+func ignoreColumns(table *core.Table, patterns []string) *core.Table {
+	ignored := make(map[string]bool)
+	for _, col := range table.Columns() {
+		if matchColumn(patterns, table, col) {
+			ignored[col.Name] = true
+		}
+	}
+	if len(ignored) == 0 {
+		return table
+	}
+
+	var cols []*core.Column
+	for _, col := range table.Columns() {
+		if !ignored[col.Name] {
+			cols = append(cols, col)
+		}
+	}
+	t := copyTable(table, cols)
+	t.PrimaryKeys = nil
+	for _, name := range table.PrimaryKeys {
+		if !ignored[name] {
+			t.PrimaryKeys = append(t.PrimaryKeys, name)
+		}
+	}
+
+	for name, index := range table.Indexes {
+		var cols []string
+		for _, c := range index.Cols {
+			if !ignored[c] {
+				cols = append(cols, c)
+			}
+		}
+		if len(cols) == 0 {
+			continue
+		}
+		t.Indexes[name] = &core.Index{IsRegular: index.IsRegular, Name: index.Name, Type: index.Type, Cols: cols}
+	}
+	return t
 }
@@ -0,0 +1,141 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/go-xorm/core"
+)
+
+// tableNames returns the names of the tables.
+func tableNames(tables []*core.Table) []string {
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		names = append(names, table.Name)
+	}
+	return names
+}
+
+func TestFilterTables(t *testing.T) {
+	tests := []struct {
+		include, exclude []string
+		want             []string
+	}{
+		{nil, nil, []string{"user", "user_log", "order", "audit_log"}},
+		{[]string{"user*"}, nil, []string{"user", "user_log"}},
+		{nil, []string{"*_log"}, []string{"user", "order"}},
+		// an excluded table is dropped even when it's included
+		{[]string{"user*"}, []string{"*_log"}, []string{"user"}},
+		{[]string{"user*", "order"}, []string{"user"}, []string{"user_log", "order"}},
+		// tables matching neither are dropped when there are includes only
+		{[]string{"invoice"}, []string{"*_log"}, []string{}},
+	}
+	for _, test := range tests {
+		tables := []*core.Table{newTable("user"), newTable("user_log"), newTable("order"), newTable("audit_log")}
+		got := tableNames(filterTables(tables, test.include, test.exclude))
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("include %v exclude %v: got %v, want %v", test.include, test.exclude, got, test.want)
+		}
+	}
+}
+
+func TestFilterTablesImports(t *testing.T) {
+	created := &core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}}
+	tables := []*core.Table{
+		newTable("user", &core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}}),
+		newTable("audit_log", created),
+	}
+	imports := genGoImports(filterTables(tables, nil, []string{"*_log"}))
+	if _, ok := imports["time"]; ok {
+		t.Errorf("imports %v of the excluded table", imports)
+	}
+}
+
+func TestSortColumnsCompositeKey(t *testing.T) {
+	table := newTable("membership",
+		&core.Column{Name: "role", SQLType: core.SQLType{Name: core.Varchar}},
+		&core.Column{Name: "user_id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+		&core.Column{Name: "created", SQLType: core.SQLType{Name: core.DateTime}},
+		&core.Column{Name: "org_id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true})
+	table.PrimaryKeys = []string{"org_id", "user_id"}
+	index := core.NewIndex("IDX_membership_role", core.IndexType)
+	index.AddColumn("role")
+	table.AddIndex(index)
+
+	tests := []struct {
+		byName, pkFirst bool
+		want            []string
+	}{
+		{false, false, []string{"org_id", "user_id", "role", "created"}},
+		{true, false, []string{"org_id", "user_id", "created", "role"}},
+		{true, true, []string{"org_id", "user_id", "created", "role"}},
+	}
+	for _, test := range tests {
+		got := sortColumns(table, test.byName, test.pkFirst)
+		if !reflect.DeepEqual(got.ColumnsSeq(), test.want) {
+			t.Errorf("byName=%v pkFirst=%v: columns %v, want %v", test.byName, test.pkFirst, got.ColumnsSeq(), test.want)
+		}
+		if !reflect.DeepEqual(got.PrimaryKeys, table.PrimaryKeys) {
+			t.Errorf("primary keys %v, want %v", got.PrimaryKeys, table.PrimaryKeys)
+		}
+		if got.Indexes["IDX_membership_role"] != index {
+			t.Errorf("indexes %v", got.Indexes)
+		}
+	}
+}
+
+func TestCheckSqlNullGeneric(t *testing.T) {
+	tests := []struct {
+		goMod string
+		ok    bool
+	}{
+		{"module app\n\ngo 1.21\n", false},
+		{"module app\n\ngo 1.20.3\n", false},
+		{"module app\n\ngo 1.22\n", true},
+		{"module app\n\ngo 1.23.1\n", true},
+		{"module app\n", true},
+		{"", true},
+	}
+	for _, test := range tests {
+		root := t.TempDir()
+		// the go.mod is looked up from the parent directories of the models
+		dir := filepath.Join(root, "models")
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if test.goMod != "" {
+			if err := ioutil.WriteFile(filepath.Join(root, "go.mod"), []byte(test.goMod), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := checkSqlNullGeneric(dir); (err == nil) != test.ok {
+			t.Errorf("%q: error %v, want ok %v", test.goMod, err, test.ok)
+		}
+	}
+}
+
+func TestWriteSourceCounts(t *testing.T) {
+	written, skipped := filesWritten, filesSkipped
+	t.Cleanup(func() { filesWritten, filesSkipped = written, skipped })
+	filesWritten, filesSkipped = 0, 0
+
+	dir := t.TempDir()
+	if err := writeSource(filepath.Join(dir, "missing", "user.go"), "package models\n"); err == nil {
+		t.Error("no error writing in a missing directory")
+	}
+	for i := 0; i < 2; i++ {
+		if err := writeSource(filepath.Join(dir, "user.go"), "package models\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if filesWritten != 1 || filesSkipped != 1 {
+		t.Errorf("%d files written and %d skipped, want 1 and 1", filesWritten, filesSkipped)
+	}
+}
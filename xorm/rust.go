@@ -0,0 +1,105 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+var (
+	RustTmpl LangTmpl = LangTmpl{
+		template.FuncMap{"Mapper": mapName,
+			"Type":    rustTypeStr,
+			"UnTitle": unTitle,
+			"Field":   rustField,
+		},
+		nil,
+		genRustImports,
+	}
+)
+
+// rustImports maps the Rust types which need a use declaration to their
+// paths.
+var rustImports = map[string]string{
+	"NaiveDate":     "chrono::NaiveDate",
+	"NaiveTime":     "chrono::NaiveTime",
+	"NaiveDateTime": "chrono::NaiveDateTime",
+}
+
+// rustKeywords can only be used as identifiers in their raw form, e.g.
+// r#type.
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true,
+	"continue": true, "dyn": true, "else": true, "enum": true, "extern": true,
+	"false": true, "fn": true, "for": true, "if": true, "impl": true,
+	"in": true, "let": true, "loop": true, "match": true, "mod": true,
+	"move": true, "mut": true, "pub": true, "ref": true, "return": true,
+	"static": true, "struct": true, "trait": true, "true": true, "type": true,
+	"unsafe": true, "use": true, "where": true, "while": true,
+}
+
+func rustTypeStr(col *core.Column) string {
+	s := rustType(col.SQLType)
+	if col.Nullable && !col.IsPrimaryKey {
+		return "Option<" + s + ">"
+	}
+	return s
+}
+
+func rustType(tp core.SQLType) string {
+	name := strings.ToUpper(tp.Name)
+	switch name {
+	case core.Bit, core.TinyInt, core.SmallInt, core.MediumInt, core.Int, core.Integer, core.Serial:
+		return "i32"
+	case core.BigInt, core.BigSerial:
+		return "i64"
+	case core.Date:
+		return "NaiveDate"
+	case core.Time:
+		return "NaiveTime"
+	case core.DateTime, core.TimeStamp, core.TimeStampz:
+		return "NaiveDateTime"
+	case core.Real, core.Float:
+		return "f32"
+	case core.Double:
+		return "f64"
+	case core.Binary, core.VarBinary, core.TinyBlob, core.Blob, core.MediumBlob, core.LongBlob, core.Bytea:
+		return "Vec<u8>"
+	case core.Bool:
+		return "bool"
+	case core.Json, core.Jsonb:
+		return "serde_json::Value"
+	default:
+		return "String"
+	}
+}
+
+// rustField returns the snake cased field name of the column, in its raw
+// form when it's a keyword.
+func rustField(col *core.Column) string {
+	name := snakeCase(col.Name)
+	if rustKeywords[name] {
+		return "r#" + name
+	}
+	return name
+}
+
+func genRustImports(tables []*core.Table) map[string]string {
+	imports := map[string]string{
+		"serde::{Deserialize, Serialize}": "serde::{Deserialize, Serialize}",
+	}
+
+	for _, table := range tables {
+		for _, col := range table.Columns() {
+			if imp, ok := rustImports[rustType(col.SQLType)]; ok {
+				imports[imp] = imp
+			}
+		}
+	}
+	return imports
+}
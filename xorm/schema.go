@@ -0,0 +1,72 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"github.com/go-xorm/core"
+)
+
+// schemasTables reads the tables of every schema and records their schema
+// in tableSchemas.
+func schemasTables(driverName, dataSourceName string, schemas []string) ([]*core.Table, error) {
+	var tables []*core.Table
+	for _, schema := range schemas {
+		tbs, err := dbTables(driverName, dataSourceName, schema)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range tbs {
+			tableSchemas[table] = schema
+		}
+		tables = append(tables, tbs...)
+	}
+	return tables, nil
+}
+
+// schemaNames returns the schemas of the tables in the order they're first
+// seen.
+func schemaNames(tables []*core.Table) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		schema := tableSchemas[table]
+		if !seen[schema] {
+			seen[schema] = true
+			names = append(names, schema)
+		}
+	}
+	return names
+}
+
+// tablesOfSchema returns the tables of the schema.
+func tablesOfSchema(tables []*core.Table, schema string) []*core.Table {
+	var res []*core.Table
+	for _, table := range tables {
+		if tableSchemas[table] == schema {
+			res = append(res, table)
+		}
+	}
+	return res
+}
+
+// renameCollisions prefixes the names of the tables which are in more than
+// one schema by their schema, e.g. users of public and auth become
+// public_users and auth_users, so their structs and files don't clash.
+func renameCollisions(tables []*core.Table) {
+	count := make(map[string]int)
+	for _, table := range tables {
+		count[table.Name]++
+	}
+	for _, table := range tables {
+		schema := tableSchemas[table]
+		if count[table.Name] < 2 || schema == "" {
+			continue
+		}
+		table.Name = schema + "_" + table.Name
+		for _, col := range table.Columns() {
+			col.TableName = table.Name
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-xorm/core"
+)
+
+func TestSchemaTables(t *testing.T) {
+	tableSchemas = make(map[*core.Table]string)
+	t.Cleanup(func() { tableSchemas = nil })
+
+	newUsers := func() *core.Table {
+		return newTable("users",
+			&core.Column{Name: "name", SQLType: core.SQLType{Name: core.Varchar}},
+			&core.Column{Name: "id", SQLType: core.SQLType{Name: core.BigInt}, IsPrimaryKey: true},
+			&core.Column{Name: "secret", SQLType: core.SQLType{Name: core.Varchar}})
+	}
+	tables := []*core.Table{newUsers(), newTable("logs"), newUsers()}
+	for i, schema := range []string{"public", "public", "auth"} {
+		tableSchemas[tables[i]] = schema
+	}
+
+	// the copies of the tables stay in their schemas
+	for i, table := range tables {
+		tables[i] = sortColumns(ignoreColumns(table, []string{"secret"}), true, true)
+	}
+	if got := schemaNames(tables); !reflect.DeepEqual(got, []string{"public", "auth"}) {
+		t.Errorf("schemas %v", got)
+	}
+	if got := tableNames(tablesOfSchema(tables, "public")); !reflect.DeepEqual(got, []string{"users", "logs"}) {
+		t.Errorf("tables of public %v", got)
+	}
+
+	renameCollisions(tables)
+	if got := tableNames(tables); !reflect.DeepEqual(got, []string{"public_users", "logs", "auth_users"}) {
+		t.Errorf("renamed tables %v", got)
+	}
+	if got := tables[2].GetColumn("id").TableName; got != "auth_users" {
+		t.Errorf("table name of the column %s", got)
+	}
+}
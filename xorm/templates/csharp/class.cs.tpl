@@ -0,0 +1,16 @@
+{{range .Imports}}using {{.}};
+{{end}}
+namespace {{.Package}}
+{
+{{range .Tables}}[Table("{{.Name}}")]
+public class {{Mapper .Name}}
+{
+{{range .Columns}}{{if .IsPrimaryKey}}[Key]
+{{end}}[Column("{{.Name}}")]
+public {{Type .}} {{Mapper .Name}} { get; set; }
+
+{{end}}
+}
+
+{{end}}
+}
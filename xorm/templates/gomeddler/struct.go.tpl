@@ -1,17 +1,191 @@
-package {{.Models}}
+package {{.Package}}
 
 {{$ilen := len .Imports}}
 {{if gt $ilen 0}}
 import (
-	{{range .Imports}}"{{.}}"{{end}}
-)
+{{range ImportGroups .Imports}}{{range .}}	{{.}}
 {{end}}
-
-{{range .Tables}}
-type {{Mapper .Name}} struct {
+{{end}})
+{{end}}
+{{with .BaseModel}}
+type BaseModel struct {
 {{$table := .}}
-{{range .ColumnsSeq}}{{$col := $table.GetColumn .}}	{{Mapper $col.Name}}	{{Type $col}} `meddler:"{{$col.Name}}{{if $col.IsPrimaryKey}},pk{{end}}{{if $col.Nullable}},zeroisnull{{end}}"`
+{{range .ColumnsSeq}}{{$col := $table.GetColumn .}}{{FieldDoc $col}}	{{Mapper $col.Name}}	{{Type $col}} `meddler:"{{$col.Name}}{{if $col.IsPrimaryKey}},pk{{end}}{{if $col.Nullable}},zeroisnull{{end}}"`{{FieldFacts $col}}
+{{end}}
+}
+{{range Sets .}}{{template "set" .}}{{end}}
+{{end}}
+{{range .Tables}}
+{{TableDoc .}}type {{TypeName .}} struct {
+{{$table := .}}{{if EmbedsBase .}}	BaseModel
 {{end}}
+{{range .ColumnsSeq}}{{$col := $table.GetColumn .}}{{if not (IsBaseCol $table $col)}}{{FieldDoc $col}}	{{Mapper $col.Name}}	{{Type $col}} `meddler:"{{$col.Name}}{{if $col.IsPrimaryKey}},pk{{end}}{{if $col.Nullable}},zeroisnull{{end}}"`{{FieldFacts $col}}
+{{end}}{{end}}
+}
+{{if $.ColConsts}}{{$name := TypeName .}}
+// The column names of {{$name}}.
+const (
+{{range ColNames .}}	{{$name}}Col{{Mapper .}} = {{printf "%q" .}}
+{{end}})
+{{end}}
+{{if $.SliceTypes}}{{$name := TypeName .}}{{$slice := SliceName .}}{{$pk := pkCols .}}
+// {{$slice}} is a slice of {{$name}}.
+type {{$slice}} []{{$name}}
+{{if eq (len $pk) 1}}{{$col := index $pk 0}}{{$field := Mapper $col.Name}}
+// {{PluralName $field}} returns the {{$field}} of every {{$name}}.
+func (s {{$slice}}) {{PluralName $field}}() []{{Type $col}} {
+	res := make([]{{Type $col}}, len(s))
+	for i := range s {
+		res[i] = s[i].{{$field}}
+	}
+	return res
+}
+{{end}}{{end}}
+{{if $.Repository}}{{$name := TypeName .}}{{$pk := pkCols .}}
+// {{$name}}Repository reads and writes {{$name}} rows.
+type {{$name}}Repository interface {
+{{if $pk}}	Get({{PKParams .}}) (*{{$name}}, error)
+{{end}}	Find() ([]*{{$name}}, error)
+	Insert(m *{{$name}}) error
+{{if $pk}}	Update(m *{{$name}}) error
+	Delete({{PKParams .}}) error
+{{end}}}
+{{end}}
+{{if $.Accessors}}{{$name := TypeName .}}{{range .Columns}}{{$type := Type .}}{{$field := Mapper .Name}}
+{{if IsPtr $type}}
+func (m *{{$name}}) Get{{$field}}() (v {{Elem $type}}) {
+	if m != nil && m.{{$field}} != nil {
+		v = *m.{{$field}}
+	}
+	return
 }
 
+func (m *{{$name}}) Set{{$field}}(v {{Elem $type}}) {
+	m.{{$field}} = &v
+}
+{{else}}
+func (m *{{$name}}) Get{{$field}}() (v {{$type}}) {
+	if m != nil {
+		v = m.{{$field}}
+	}
+	return
+}
+
+func (m *{{$name}}) Set{{$field}}(v {{$type}}) {
+	m.{{$field}} = v
+}
+{{end}}{{end}}{{end}}
+{{if $.OrEmpty}}{{$name := TypeName .}}{{range .Columns}}{{$type := Type .}}{{$field := Mapper .Name}}{{if IsPtr $type}}
+func (m *{{$name}}) {{$field}}OrEmpty() (v {{Elem $type}}) {
+	if m != nil && m.{{$field}} != nil {
+		v = *m.{{$field}}
+	}
+	return
+}
+{{end}}{{end}}{{end}}
+{{range Enums .}}{{template "enum" .}}{{end}}
+{{range Sets .}}{{template "set" .}}{{end}}
 {{end}}
+{{range .Enums}}
+// {{.Name}} holds the options of column {{.Column}} of table {{.Table}}.{{template "enum" .}}{{end}}
+{{define "enum"}}
+type {{.Name}} string
+
+const (
+{{$enum := .}}{{range .Values}}	{{.Name}} {{$enum.Name}} = {{printf "%q" .Value}}
+{{end}})
+{{if .Scanner}}
+// Valid reports whether e is one of the options of {{.Name}}.
+func (e {{.Name}}) Valid() bool {
+	switch e {
+	case {{range $i, $v := .Values}}{{if $i}}, {{end}}{{$v.Name}}{{end}}:
+		return true
+	}
+	return false
+}
+
+// Scan implements sql.Scanner, it fails for the values which aren't options.
+func (e *{{.Name}}) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+		*e = ""
+		return nil
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("{{.Name}}: cannot scan %T", src)
+	}
+	if !{{.Name}}(s).Valid() {
+		return fmt.Errorf("{{.Name}}: invalid value %q", s)
+	}
+	*e = {{.Name}}(s)
+	return nil
+}
+
+// Value implements driver.Valuer, it fails for the values which aren't
+// options.
+func (e {{.Name}}) Value() (driver.Value, error) {
+	if !e.Valid() {
+		return nil, fmt.Errorf("{{.Name}}: invalid value %q", string(e))
+	}
+	return string(e), nil
+}
+{{end}}{{end}}
+{{define "set"}}
+// {{.Name}} holds the options of column {{.Column}} of table {{.Table}}, stored
+// joined by commas.
+type {{.Name}} []{{.Elem}}
+
+// FromDB implements core.Conversion.
+func (s *{{.Name}}) FromDB(data []byte) error {
+	if data == nil {
+		*s = nil
+		return nil
+	}
+	*s = {{.Name}}{}
+	if len(data) == 0 {
+		return nil
+	}
+	for _, v := range strings.Split(string(data), ",") {
+		*s = append(*s, {{.Elem}}(v))
+	}
+	return nil
+}
+
+// ToDB implements core.Conversion.
+func (s {{.Name}}) ToDB() ([]byte, error) {
+	if s == nil {
+		return nil, nil
+	}
+	vs := make([]string, len(s))
+	for i, v := range s {
+		vs[i] = string(v)
+	}
+	return []byte(strings.Join(vs, ",")), nil
+}
+
+// Scan implements sql.Scanner.
+func (s *{{.Name}}) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		return s.FromDB(nil)
+	case string:
+		return s.FromDB([]byte(v))
+	case []byte:
+		return s.FromDB(v)
+	}
+	return fmt.Errorf("{{.Name}}: cannot scan %T", src)
+}
+
+// Value implements driver.Valuer.
+func (s {{.Name}}) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+	data, err := s.ToDB()
+	return string(data), err
+}
+{{end}}
\ No newline at end of file
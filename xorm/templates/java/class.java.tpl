@@ -0,0 +1,19 @@
+package {{.Package}};
+
+{{range .Imports}}import {{.}};
+{{end}}
+{{range .Tables}}
+public class {{Mapper .Name}} {
+{{range .Columns}}private {{Type .}} {{UnTitle (Mapper .Name)}};
+{{end}}
+{{range .Columns}}{{$name := Mapper .Name}}
+public {{Type .}} get{{$name}}() {
+return {{UnTitle $name}};
+}
+
+public void set{{$name}}({{Type .}} {{UnTitle $name}}) {
+this.{{UnTitle $name}} = {{UnTitle $name}};
+}
+{{end}}
+}
+{{end}}
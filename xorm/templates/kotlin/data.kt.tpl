@@ -0,0 +1,9 @@
+package {{.Package}}
+{{if .Imports}}
+{{range .Imports}}import {{.}}
+{{end}}{{end}}{{range .Tables}}
+data class {{Mapper .Name}}(
+{{range $i, $col := .Columns}}{{if $i}},
+{{end}}    val {{Ident (UnTitle (Mapper $col.Name))}}: {{Type $col}}{{if $col.Nullable}}{{if not $col.IsPrimaryKey}} = null{{end}}{{end}}{{end}}
+)
+{{end}}
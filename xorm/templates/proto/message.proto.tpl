@@ -0,0 +1,10 @@
+syntax = "proto3";
+
+package {{.Package}};
+{{range .Imports}}
+import "{{.}}";{{end}}
+{{range .Tables}}
+message {{Mapper .Name}} {
+{{range $i, $col := .Columns}}  {{Type $col}} {{Snake $col.Name}} = {{Inc $i}};
+{{end}}}
+{{end}}
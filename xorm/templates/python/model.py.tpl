@@ -0,0 +1,8 @@
+from dataclasses import dataclass
+{{range .Imports}}{{.}}
+{{end}}{{range .Tables}}
+
+@dataclass
+class {{Mapper .Name}}:
+{{range .Columns}}    {{.Name}}: {{Type .}}
+{{end}}{{end}}
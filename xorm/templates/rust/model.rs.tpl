@@ -0,0 +1,8 @@
+{{range .Imports}}use {{.}};
+{{end}}{{range .Tables}}
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct {{Mapper .Name}} {
+{{range .Columns}}{{$field := Field .}}{{if ne $field .Name}}    #[serde(rename = "{{.Name}}")]
+{{end}}    pub {{$field}}: {{Type .}},
+{{end}}}
+{{end}}
@@ -0,0 +1,5 @@
+{{range .Tables}}export interface {{Mapper .Name}} {
+{{range .Columns}}	{{.Name}}: {{Type .}};
+{{end}}}
+
+{{end}}
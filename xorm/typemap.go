@@ -0,0 +1,195 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-xorm/core"
+)
+
+// TypeMap holds the custom Go types given by -type-map. The keys of
+// Columns are column names or path.Match patterns, the keys of Types are
+// SQL type names, and the values are Go types which may be qualified by
+// their full import path, e.g. github.com/shopspring/decimal.Decimal.
+// Regexps match column names by regular expressions in their order.
+type TypeMap struct {
+	Columns map[string]string `json:"columns"`
+	Types   map[string]string `json:"types"`
+	Regexps []*TypeRegexp     `json:"regexps"`
+}
+
+// TypeRegexp is the Go type of the columns whose names match Pattern.
+type TypeRegexp struct {
+	Pattern string `json:"pattern"`
+	Type    string `json:"type"`
+
+	re *regexp.Regexp
+}
+
+var typeMap *TypeMap
+
+// loadTypeMap reads a type map from a JSON file, or from a TOML file with
+// [columns] and [types] tables when the file has a .toml extension.
+func loadTypeMap(f string) (*TypeMap, error) {
+	bts, err := ioutil.ReadFile(f)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &TypeMap{
+		Columns: make(map[string]string),
+		Types:   make(map[string]string),
+	}
+	if strings.ToLower(path.Ext(f)) == ".toml" {
+		err = parseTomlTypeMap(string(bts), m)
+	} else {
+		err = json.Unmarshal(bts, m)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", f, err)
+	}
+
+	types := make(map[string]string, len(m.Types))
+	for k, v := range m.Types {
+		types[strings.ToUpper(k)] = v
+	}
+	m.Types = types
+
+	goTypes := make([]string, 0, len(m.Columns)+len(m.Types)+len(m.Regexps))
+	for _, r := range m.Regexps {
+		if r.re, err = regexp.Compile(r.Pattern); err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		}
+		goTypes = append(goTypes, r.Type)
+	}
+	for _, t := range m.Columns {
+		goTypes = append(goTypes, t)
+	}
+	for _, t := range m.Types {
+		goTypes = append(goTypes, t)
+	}
+	for _, t := range goTypes {
+		if _, _, err := splitQualifiedType(t); err != nil {
+			return nil, fmt.Errorf("%s: %v", f, err)
+		}
+	}
+	return m, nil
+}
+
+func parseTomlTypeMap(src string, m *TypeMap) error {
+	var section map[string]string
+	var regexps bool
+	for i, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(strings.TrimRight(line, "\r"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch line {
+		case "[columns]":
+			section, regexps = m.Columns, false
+			continue
+		case "[types]":
+			section, regexps = m.Types, false
+			continue
+		case "[regexps]":
+			section, regexps = nil, true
+			continue
+		}
+
+		vs := strings.SplitN(line, "=", 2)
+		if len(vs) != 2 || (section == nil && !regexps) {
+			return fmt.Errorf("line %d: invalid entry %q", i+1, line)
+		}
+		if regexps {
+			// the order of the file is kept
+			m.Regexps = append(m.Regexps, &TypeRegexp{Pattern: unquote(vs[0]), Type: unquote(vs[1])})
+			continue
+		}
+		section[unquote(vs[0])] = unquote(vs[1])
+	}
+	return nil
+}
+
+func unquote(s string) string {
+	return strings.Trim(strings.TrimSpace(s), `"'`)
+}
+
+// lookup returns the Go type configured for the column. Column names are
+// matched first, then column name patterns, then the regular expressions
+// in order, then the SQL type name.
+func (m *TypeMap) lookup(col *core.Column) (string, bool) {
+	if t, ok := m.Columns[col.Name]; ok {
+		return t, true
+	}
+
+	patterns := make([]string, 0, len(m.Columns))
+	for p := range m.Columns {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, col.Name); ok {
+			return m.Columns[p], true
+		}
+	}
+
+	for _, r := range m.Regexps {
+		if r.re.MatchString(col.Name) {
+			return r.Type, true
+		}
+	}
+
+	t, ok := m.Types[strings.ToUpper(col.SQLType.Name)]
+	return t, ok
+}
+
+// overrideType returns the Go type and its import path when the column has
+// a custom type in the type map.
+func overrideType(col *core.Column) (string, string, bool) {
+	if typeMap == nil {
+		return "", "", false
+	}
+	t, ok := typeMap.lookup(col)
+	if !ok {
+		return "", "", false
+	}
+	// the types are checked by loadTypeMap
+	typ, pkg, _ := splitQualifiedType(t)
+	return typ, pkg, true
+}
+
+// splitQualifiedType splits a Go type qualified by its import path, such as
+// *github.com/shopspring/decimal.Decimal, into the type as written in the
+// generated code (*decimal.Decimal) and the import path. A qualifier
+// without a slash, like json in json.RawMessage, is resolved by
+// goImportPaths and it's an error when it isn't there.
+func splitQualifiedType(t string) (string, string, error) {
+	name := strings.TrimLeft(t, "*[]")
+	prefix := t[:len(t)-len(name)]
+
+	slash := strings.LastIndex(name, "/")
+	dot := strings.LastIndex(name, ".")
+	if dot <= slash {
+		return t, "", nil
+	}
+
+	pkg := name[:dot]
+	if slash < 0 {
+		p, ok := goImportPaths[pkg]
+		if !ok {
+			return t, "", fmt.Errorf("unknown package %s of type %s, qualify the type by its import path", pkg, t)
+		}
+		pkg = p
+	}
+	return prefix + path.Base(pkg) + name[dot:], pkg, nil
+}
@@ -0,0 +1,65 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSplitQualifiedType(t *testing.T) {
+	tests := []struct {
+		t, typ, pkg string
+	}{
+		{"string", "string", ""},
+		{"[]byte", "[]byte", ""},
+		{"json.RawMessage", "json.RawMessage", "encoding/json"},
+		{"*sql.NullString", "*sql.NullString", "database/sql"},
+		{"*github.com/shopspring/decimal.Decimal", "*decimal.Decimal", "github.com/shopspring/decimal"},
+		{"[]github.com/google/uuid.UUID", "[]uuid.UUID", "github.com/google/uuid"},
+	}
+	for _, test := range tests {
+		typ, pkg, err := splitQualifiedType(test.t)
+		if err != nil || typ != test.typ || pkg != test.pkg {
+			t.Errorf("%s: got %s %s %v, want %s %s", test.t, typ, pkg, err, test.typ, test.pkg)
+		}
+	}
+
+	if _, _, err := splitQualifiedType("mytypes.Money"); err == nil {
+		t.Error("no error for an unknown qualifier")
+	}
+}
+
+func TestLoadTypeMap(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		f := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(f, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return f
+	}
+
+	m, err := loadTypeMap(write("types.json", `{"types": {"json": "json.RawMessage"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := m.Types["JSON"]; got != "json.RawMessage" {
+		t.Errorf("type of JSON %q", got)
+	}
+
+	for name, content := range map[string]string{
+		"columns.json": `{"columns": {"price": "money.Amount"}}`,
+		"types.toml":   "[types]\njson = \"jsonx.Raw\"\n",
+		"regexps.json": `{"regexps": [{"pattern": "_at$", "type": "civil.DateTime"}]}`,
+	} {
+		_, err := loadTypeMap(write(name, content))
+		if err == nil || !strings.Contains(err.Error(), "unknown package") {
+			t.Errorf("%s: error %v, want an unknown package", name, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2017 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"strings"
+	"text/template"
+
+	"github.com/go-xorm/core"
+)
+
+var (
+	TypeScriptTmpl LangTmpl = LangTmpl{
+		template.FuncMap{"Mapper": mapName,
+			"Type":    typeScriptTypeStr,
+			"UnTitle": unTitle,
+		},
+		nil,
+		genTypeScriptImports,
+	}
+)
+
+func typeScriptTypeStr(col *core.Column) string {
+	s := typeScriptType(col.SQLType)
+	if col.Nullable && !col.IsPrimaryKey {
+		return s + " | null"
+	}
+	return s
+}
+
+func typeScriptType(tp core.SQLType) string {
+	name := strings.ToUpper(tp.Name)
+	switch name {
+	case core.Bit, core.TinyInt, core.SmallInt, core.MediumInt, core.Int, core.Integer, core.Serial,
+		core.BigInt, core.BigSerial, core.Real, core.Float, core.Double:
+		return "number"
+	case core.Date, core.DateTime, core.Time, core.TimeStamp, core.TimeStampz:
+		return "Date"
+	case core.Bool:
+		return "boolean"
+	case core.Json, core.Jsonb:
+		return "any"
+	default:
+		return "string"
+	}
+}
+
+func genTypeScriptImports(tables []*core.Table) map[string]string {
+	return map[string]string{}
+}